@@ -2,24 +2,29 @@ package cmd
 
 import (
 	"fmt"
-	"io/ioutil"
-	"os"
-	"strings"
-	"time"
 
 	"github.com/blang/semver"
-	"github.com/chanzuckerberg/bff/pkg/util"
-	"github.com/kr/pretty"
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/chanzuckerberg/bff/pkg/config"
 	"github.com/pkg/errors"
-	prompt "github.com/segmentio/go-prompt"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	git "gopkg.in/src-d/go-git.v4"
-	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
 func init() {
 	rootCmd.AddCommand(bumpCmd)
+	addBumpFlags(bumpCmd)
+}
+
+// addBumpFlags adds the flags shared by bump and release
+func addBumpFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("yes", "y", false, "Do not prompt, assume yes")
+	cmd.Flags().Bool("dry-run", false, "Print the proposed version without changing anything")
+	cmd.Flags().Bool("allow-dirty", false, "Release even if the working directory has uncommitted changes")
+	cmd.Flags().String("pre", "", "Release a pre-release on the given channel, e.g. alpha, beta or rc")
+	cmd.Flags().Bool("push", false, "Push the release commit and tags to the remote's default branch")
+	addComponentFlags(cmd, true)
 }
 
 var (
@@ -30,159 +35,215 @@ var (
 var bumpCmd = &cobra.Command{
 	Use:   "bump",
 	Short: "Bump the version based on git history since last version.",
+	Long: fmt.Sprintf(`Bump the version based on git history since last version.
+
+Exit codes: %d released (or would release, with --dry-run), %d error, %d nothing to release.`,
+		ExitReleased, ExitError, ExitNothingToRelease),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		repo, err := git.PlainOpen(".")
-		if err != nil {
-			return fmt.Errorf("unable to open git repo %w", err)
-		}
+		return runBump(cmd, false)
+	},
+}
 
-		options := &git.FetchOptions{
-			Tags:     git.AllTags,
-			Progress: os.Stdout,
-		}
-		err = repo.Fetch(options)
+// runBump releases the next version of the selected components: it updates their version files, and their
+// changelogs if withChangelog, in one release commit per component or group and tags it
+func runBump(cmd *cobra.Command, withChangelog bool) error {
+	assumeYes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+	allowDirty, err := cmd.Flags().GetBool("allow-dirty")
+	if err != nil {
+		return err
+	}
+	pre, err := cmd.Flags().GetString("pre")
+	if err != nil {
+		return err
+	}
+	push, err := cmd.Flags().GetBool("push")
+	if err != nil {
+		return err
+	}
 
-		if err != nil && err != git.NoErrAlreadyUpToDate {
-			return fmt.Errorf("unable to fetch %w", err)
-		}
+	repo, err := git.PlainOpen(".")
+	if err != nil {
+		return fmt.Errorf("unable to open git repo %w", err)
+	}
 
-		w, err := repo.Worktree()
-		if err != nil {
-			return fmt.Errorf("Unable to open worktree %w", err)
-		}
+	err = fetchRemote(repo)
+	if err != nil {
+		return err
+	}
 
-		s, err := w.Status()
-		if err != nil {
-			return fmt.Errorf("Unable to get git status %w", err)
-		}
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("Unable to open worktree %w", err)
+	}
 
-		if !s.IsClean() {
-			// HACK(el): go-git does not appear to handle nested .gitignores well
-			// for now, prompt users instead of erroring out immediately
-			ignore := prompt.Confirm("your working directory appears to be dirty (uncommited changes), are you sure you want to proceed?")
-			if !ignore {
-				return errors.New("please release only from a clean working directory (no uncommitted changes)")
-			}
-		}
+	err = checkClean(w, allowDirty || dryRun, assumeYes)
+	if err != nil {
+		return err
+	}
 
-		defaultBranchCommit, err := util.VerifyDefaultBranch(repo, defaultBranchRef)
-		if err != nil {
-			return err
-		}
-		latestVersionTag, latestVersionHash, err := util.LatestTagCommitHash(repo, defaultBranchRef)
-		if err != nil {
-			return err
-		}
+	units, all, err := targetUnits(cmd)
+	if err != nil {
+		return err
+	}
+	branchRef, err := defaultBranchRef()
+	if err != nil {
+		return err
+	}
 
-		f, err := os.Open("VERSION")
-		if err != nil {
-			return err
-		}
-		d, err := ioutil.ReadAll(f)
+	releaseUnits, unitPlans := []releaseUnit{}, [][]*releasePlan{}
+	for _, unit := range units {
+		plans, err := planUnit(repo, branchRef, unit, pre)
 		if err != nil {
 			return err
 		}
-		fileVersion := strings.TrimSpace(string(d))
-
-		if latestVersionTag != nil && *latestVersionTag != fileVersion {
-			if latestVersionTag == nil {
-				fmt.Printf("latestVersionTag %#v\n", latestVersionTag)
-			} else {
-				fmt.Printf("latestVersionTag %#v\n", *latestVersionTag)
-			}
-			fmt.Printf("fileversion %#v\n", fileVersion)
-			return errors.New("tag does not match VERSION file")
-		}
 
-		breaking, feature := false, false
-
-		// TODO refactor to use Log
-		// TODO check that we actually have commits since the last release
-		commit := defaultBranchCommit
-		for {
-			if commit.Hash.String() == latestVersionHash.String() {
-				break
-			}
-
-			if strings.Contains(commit.Message, "[breaking]") {
-				breaking = true
-			}
-
-			if strings.Contains(commit.Message, "[feature]") {
-				feature = true
-			}
-
-			if len(commit.ParentHashes) == 0 {
-				// When we get here we should be at the beginning of this repo's history
-				break
-			}
-			commit, err = util.GetLatestParentCommit(commit)
+		for _, plan := range plans {
+			fileVersion, err := readVersionFile(plan.Component.VersionFile)
 			if err != nil {
 				return err
 			}
-		}
-
-		pretty.Print(feature)
 
-		// at this point, if latestVersionTag == nil then set to 0.0.1
-		if latestVersionTag == nil {
-			latestVersionTag = &initialVersion
+			if plan.LatestTag != "" && plan.LatestTag != fileVersion {
+				fmt.Printf("latestVersionTag %#v\n", plan.LatestTag)
+				fmt.Printf("fileversion %#v\n", fileVersion)
+				return errors.Errorf("tag does not match %s file", plan.Component.VersionFile)
+			}
 		}
 
-		ver, err := semver.Make(*latestVersionTag)
-		if err != nil {
-			return err
+		if nothingToRelease(plans) {
+			if !all {
+				return ErrNothingToRelease
+			}
+			continue
 		}
+		releaseUnits = append(releaseUnits, unit)
+		unitPlans = append(unitPlans, plans)
+	}
+	if len(unitPlans) == 0 {
+		return ErrNothingToRelease
+	}
 
-		releaseType := ReleaseType(ver.Major, breaking, feature)
-
-		newVer := NewVersion(ver, releaseType)
-
-		fmt.Printf("release type is: %s\n", releaseType)
-		fmt.Printf("current version is: %s\n", ver)
-		fmt.Printf("proposed version is: %s\n", newVer)
-		procede := prompt.Confirm("proceed?")
-		if !procede {
-			logrus.Info("ok, quitting")
-			return nil
+	for _, plans := range unitPlans {
+		for _, plan := range plans {
+			if plan.Component.Name != "" {
+				fmt.Printf("component: %s\n", plan.Component.Name)
+			}
+			fmt.Printf("release type is: %s\n", plan.ReleaseType)
+			fmt.Printf("current version is: %s\n", plan.CurrentVersion())
+			fmt.Printf("proposed version is: %s\n", plan.Next)
 		}
-
-		f, err = os.OpenFile("VERSION", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	}
+	if dryRun {
+		return nil
+	}
+	tagger, err := newReleaseTagger(repo)
+	if err != nil {
+		return err
+	}
+	var publisher *githubPublisher
+	if cfg.GitHub.Release && push {
+		publisher, err = newGitHubPublisher(repo)
 		if err != nil {
 			return err
 		}
+	} else if cfg.GitHub.Release {
+		logrus.Warn("GitHub releases are only published for tags pushed with --push")
+	}
+	procede, err := confirm("proceed?", assumeYes)
+	if err != nil {
+		return err
+	}
+	if !procede {
+		logrus.Info("ok, quitting")
+		return nil
+	}
 
-		_, err = f.WriteString(newVer.String())
-		if err != nil {
-			return err
+	// the changelogs are computed up front, as the release commits move HEAD ahead of the remote's default branch
+	unitReleases := [][][]changelog.Release{}
+	if withChangelog {
+		for _, plans := range unitPlans {
+			releases := [][]changelog.Release{}
+			for _, plan := range plans {
+				planned, err := planReleases(repo, plan)
+				if err != nil {
+					return err
+				}
+				releases = append(releases, planned)
+			}
+			unitReleases = append(unitReleases, releases)
 		}
+	}
 
-		_, err = w.Add("VERSION")
-		if err != nil {
-			return err
+	// each component, or each group with all its members, is released in its own commit
+	tags := []string{}
+	// GitHub releases are published once their tags are pushed
+	type githubRelease struct {
+		component config.Component
+		version   string
+		notes     string
+	}
+	githubReleases := []githubRelease{}
+	for i, plans := range unitPlans {
+		newVer := plans[0].Next.String()
+		paths := []string{}
+		for j, plan := range plans {
+			written, err := writeVersionFiles(plan.Component, newVer)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, written...)
+
+			if withChangelog {
+				err = writeChangelog(repo, plan.Component, unitReleases[i][j], newVer)
+				if err != nil {
+					return err
+				}
+				paths = append(paths, plan.Component.ChangelogFile)
+			}
 		}
 
-		name, email, err := util.GetGitAuthor()
+		commitHash, err := commitRelease(w, releaseUnits[i].Name, newVer, paths...)
 		if err != nil {
-			fmt.Printf("git author name %s", name)
-			fmt.Printf("git author email %s", email)
 			return err
 		}
-		opts := &git.CommitOptions{
-			Author: &object.Signature{
-				Name:  name,
-				Email: email,
-				When:  time.Now(),
-			},
+		for _, plan := range plans {
+			notes, err := releaseNotes(plan.Component, newVer, plan.Commits)
+			if err != nil {
+				return err
+			}
+			err = tagger.tag(plan.Component, newVer, commitHash, notes)
+			if err != nil {
+				return err
+			}
+			tags = append(tags, plan.Component.TagName(newVer))
+			if publisher != nil {
+				githubReleases = append(githubReleases, githubRelease{plan.Component, newVer, notes})
+			}
 		}
-		commitHash, err := w.Commit(fmt.Sprintf("release version %s", newVer), opts)
+	}
+
+	if !push {
+		return nil
+	}
+	err = pushRelease(repo, branchRef, unitPlans[0][0].Head.Hash, tags)
+	if err != nil {
+		return err
+	}
+	for _, r := range githubReleases {
+		err = publisher.publish(r.component, r.version, r.notes)
 		if err != nil {
 			return err
 		}
-		_, err = repo.CreateTag(fmt.Sprintf("v%s", newVer), commitHash, nil)
-		return err
-	},
+	}
+	return nil
 }
 
 // ReleaseType will calculate whether the next release should be major, minor or patch
@@ -218,3 +279,17 @@ func NewVersion(ver semver.Version, releaseType string) semver.Version {
 	}
 	return ver
 }
+
+// NewPreRelease returns the pre-release version core-channel.N for the next N after the given latest pre-release number
+func NewPreRelease(core semver.Version, channel string, latest uint64) (semver.Version, error) {
+	pre, err := semver.NewPRVersion(channel)
+	if err != nil {
+		return core, errors.Wrapf(err, "invalid pre-release channel %s", channel)
+	}
+	if pre.IsNum {
+		return core, errors.Errorf("invalid pre-release channel %s, must not be numeric", channel)
+	}
+	core.Pre = []semver.PRVersion{pre, {VersionNum: latest + 1, IsNum: true}}
+	core.Build = nil
+	return core, nil
+}
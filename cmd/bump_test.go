@@ -54,3 +54,34 @@ func TestNewVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPreRelease(t *testing.T) {
+	type args struct {
+		core    semver.Version
+		channel string
+		latest  uint64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{"first rc", args{semver.MustParse("1.4.0"), "rc", 0}, "1.4.0-rc.1", false},
+		{"next rc", args{semver.MustParse("1.4.0"), "rc", 2}, "1.4.0-rc.3", false},
+		{"beta", args{semver.MustParse("0.2.0"), "beta", 0}, "0.2.0-beta.1", false},
+		{"numeric channel", args{semver.MustParse("1.4.0"), "1", 0}, "", true},
+		{"invalid channel", args{semver.MustParse("1.4.0"), "r c", 0}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cmd.NewPreRelease(tt.args.core, tt.args.channel, tt.args.latest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPreRelease() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("NewPreRelease() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
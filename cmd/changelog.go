@@ -1,160 +1,806 @@
 package cmd
 
 import (
-	"bufio"
-	"bytes"
+	"encoding/json"
 	"fmt"
-	"math"
+	"io"
+	"io/ioutil"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
-	"syscall"
 	"time"
 
-	"gopkg.in/src-d/go-git.v4/plumbing/storer"
-
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/chanzuckerberg/bff/pkg/commits"
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/chanzuckerberg/bff/pkg/forge"
+	"github.com/chanzuckerberg/bff/pkg/mailmap"
 	"github.com/chanzuckerberg/bff/pkg/util"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	yaml "gopkg.in/yaml.v2"
 )
 
 func init() {
 	rootCmd.AddCommand(changelogCmd)
 
 	// TODO: changelogCmd.Flags().BoolP("breaking", "b", false, "Breaking release")
+	changelogCmd.Flags().String("from", "", "Only add the releases after this tag or revision")
+	changelogCmd.Flags().String("to", "", "Only add the releases up to this tag or revision (default HEAD)")
+	changelogCmd.Flags().Bool("backfill", false, "Regenerate the whole changelog, with a section for every version tag")
+	changelogCmd.Flags().StringP("format", "f", "markdown", "Output format, markdown, json or yaml. json and yaml are printed to stdout")
+	changelogCmd.Flags().Bool("stdout", false, "Print the releases to stdout instead of writing the changelog file")
+	changelogCmd.Flags().String("pre", "", "Compute the next version as a pre-release on the given channel, e.g. alpha, beta or rc")
+	addComponentFlags(changelogCmd, true)
 }
 
 var changelogCmd = &cobra.Command{
-	Use:   "changelog next-version",
+	Use:   "changelog [next-version]",
 	Short: "Generate changelog entries based on git history",
+	Long: `Generate changelog entries based on git history.
+
+By default a section for next-version is added, listing the commits since the latest release. If next-version
+is omitted, it is the version bump would release, or pre-release with --pre.
+
+With --from and/or --to, a section is added for every version tag in that range, dated by the tagged commit.
+Tags can be given with or without the tag prefix, e.g. v1.2.0 or 1.2.0. Commits after the last tag in the
+range make up the section of next-version, if given. next-version must not be tagged yet, unless --to is its tag.
+
+With --backfill, the changelog is regenerated from the whole history, keeping what comes before the first
+release section. This gets repos that adopt bff late a complete changelog. The existing section of every
+tagged version is replaced, hand edits included. Sections of versions without a stable tag, such as
+pre-releases, are kept.
+
+With --all, a section is added to the changelog of every component with changes since its
+last release, for the version bump would release, so next-version must be omitted.
+
+With --stdout, the sections are printed instead of written to the changelog file, each component's under a
+"# <component>" heading if there are several. With --format json or yaml, the releases are printed as data:
+a list of releases with their component, version, date, previous tag and commits.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return errors.New("please supply release version, e.g. `bff changelog 0.20.3`")
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
 		}
-		newRelease := args[0]
-		repo, err := git.PlainOpen(".")
+		to, err := cmd.Flags().GetString("to")
 		if err != nil {
-			return errors.Wrap(err, "could not open git repo")
+			return err
 		}
-		v, tagCommitHash, err := util.LatestTagCommitHash(repo, defaultBranchRef)
+		backfill, err := cmd.Flags().GetBool("backfill")
 		if err != nil {
-			return errors.Wrap(err, "unable to retrieve latest tag's commit hash")
+			return err
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		stdout, err := cmd.Flags().GetBool("stdout")
+		if err != nil {
+			return err
+		}
+		switch format {
+		case "markdown":
+		case "json", "yaml":
+			stdout = true
+		default:
+			return errors.Errorf("unknown format %s, must be markdown, json or yaml", format)
+		}
+		ranged := from != "" || to != ""
+
+		units, all, err := targetUnits(cmd)
+		if err != nil {
+			return err
+		}
+		if backfill && ranged {
+			return errors.New("--backfill covers the whole history, it cannot be combined with --from or --to")
+		}
+		if all && ranged {
+			return errors.New("--from and --to select the tags of a single component, they cannot be combined with --all")
+		}
+		if all && len(args) != 0 {
+			return errors.New("with --all the next version is computed for each component, please omit it")
+		}
+		pre, err := cmd.Flags().GetString("pre")
+		if err != nil {
+			return err
+		}
+		nextVersion := ""
+		if len(args) == 1 {
+			nextVersion = args[0]
+		}
+		// without a next version, the changelog is for the version bump would release
+		compute := !ranged && !backfill && nextVersion == ""
+		if pre != "" && !compute {
+			return errors.New("--pre only applies when the next version is computed, please omit it")
 		}
-		fmt.Printf("Last commit: %s (version: %s)\n", tagCommitHash.String()[:8], *v)
 
-		cIter, err := repo.Log(&git.LogOptions{
-			Order: git.LogOrderCommitterTime,
-		})
+		repo, err := git.PlainOpen(".")
+		if err != nil {
+			return errors.Wrap(err, "could not open git repo")
+		}
+		branchRef, err := defaultBranchRef()
 		if err != nil {
-			return errors.Wrap(err, "failed to retrieve commit history")
+			return err
 		}
 
-		releaseLog := bytes.NewBuffer(nil)
+		// with --stdout anything but the releases goes to stderr
+		status := io.Writer(os.Stdout)
+		if stdout {
+			status = os.Stderr
+		}
+		printed := []changelog.Release{}
+		output := func(component config.Component, releases []changelog.Release, nextVersion string) error {
+			switch {
+			case stdout:
+				printed = append(printed, releases...)
+				return nil
+			case backfill:
+				return backfillChangelog(repo, component, releases, nextVersion)
+			default:
+				fmt.Printf("Updating %s changelog with %d releases\n", component.DisplayName(), len(releases))
+				return writeReleases(repo, component, releases, nextVersion)
+			}
+		}
 
-		// A release begins with a release header line "## 0.22.0 2019-06-04\n", followed by a list of commits
-		releaseHeader := fmt.Sprintf("## %s %s\n", newRelease, time.Now().Format("2006-01-02"))
-		fmt.Fprintln(releaseLog, releaseHeader)
+		for _, unit := range units {
+			if !compute {
+				for _, component := range unit.Components {
+					nextVersion, err := untaggedVersion(repo, component, to, nextVersion)
+					if err != nil {
+						return err
+					}
+					var releases []changelog.Release
+					switch {
+					case backfill:
+						releases, err = backfillReleases(repo, component, nextVersion)
+					case ranged:
+						releases, err = rangeReleases(repo, component, from, to, nextVersion)
+					default:
+						releases, err = latestReleases(repo, branchRef, component, nextVersion)
+					}
+					if err != nil {
+						return err
+					}
+					err = output(component, releases, nextVersion)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
 
-		// Build the list of commits
-		err = cIter.ForEach(func(commit *object.Commit) error {
-			if tagCommitHash != nil && commit.Hash == *tagCommitHash {
-				return storer.ErrStop
+			plans, err := planUnit(repo, branchRef, unit, pre)
+			if err != nil {
+				return err
+			}
+			if nothingToRelease(plans) {
+				if !all {
+					return ErrNothingToRelease
+				}
+				fmt.Fprintf(status, "Nothing to release for %s\n", unit.Name)
+				continue
 			}
+			for _, plan := range plans {
+				fmt.Fprintf(status, "Next %s version is %s (%s release)\n", plan.Component.DisplayName(), plan.Next, plan.ReleaseType)
+				releases, err := planReleases(repo, plan)
+				if err != nil {
+					return err
+				}
+				err = output(plan.Component, releases, plan.Next.String())
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if stdout {
+			return printReleases(format, printed)
+		}
+		fmt.Println("Done.")
+		return nil
+	},
+}
+
+// writeChangelog adds the sections of releases, newest first, to the component's changelog
+func writeChangelog(repo *git.Repository, component config.Component, releases []changelog.Release, newRelease string) error {
+	if component.Name != "" {
+		fmt.Printf("Updating %s changelog with release %s\n", component.Name, component.TagName(newRelease))
+	} else {
+		fmt.Printf("Updating changelog with release %s\n", component.TagName(newRelease))
+	}
+	return writeReleases(repo, component, releases, newRelease)
+}
+
+// planReleases returns the release of the plan's next version, with the commits touching the component since
+// its latest stable version tag. It only looks at the planned commits, so it works after the release commits of
+// other units.
+func planReleases(repo *git.Repository, plan *releasePlan) ([]changelog.Release, error) {
+	return changelogReleases(repo, plan.Component, plan.Head, plan.TagHash, plan.Next.String())
+}
+
+// latestReleases returns the release of newRelease, with the commits touching the component since its latest
+// version tag
+func latestReleases(repo *git.Repository, branchRef string, component config.Component, newRelease string) ([]changelog.Release, error) {
+	v, tagCommitHash, err := util.LatestTagCommitHash(repo, branchRef, component.TagPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve latest tag's commit hash")
+	}
+	logrus.Infof("Last commit: %s (version: %s)", tagCommitHash.String()[:8], *v)
+
+	head, err := headCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+	return changelogReleases(repo, component, head, *tagCommitHash, newRelease)
+}
 
-			_, err = fmt.Fprintln(releaseLog, GetCommitLog(commit))
-			return errors.Wrap(err, "could not append to changelog")
-		})
+// rangeReleases returns a release for every version tag after from and up to to. from and to are tags, with or
+// without the component's tag prefix, or any revision.
+func rangeReleases(repo *git.Repository, component config.Component, from string, to string, nextVersion string) ([]changelog.Release, error) {
+	fromHash := plumbing.ZeroHash
+	if from != "" {
+		h, err := resolveChangelogRef(repo, component, from)
 		if err != nil {
-			return errors.Wrap(err, "error generating changelog")
+			return nil, err
 		}
+		fromHash = h
+	}
+
+	toCommit, err := resolveChangelogTo(repo, component, to)
+	if err != nil {
+		return nil, err
+	}
+	return changelogReleases(repo, component, toCommit, fromHash, nextVersion)
+}
 
-		fmt.Printf("Updating changelog with release v%s\n", newRelease)
-		err = UpdateChangeLogFile(releaseLog.String())
+// resolveChangelogTo returns the commit of to, a tag with or without the component's tag prefix or any revision, or HEAD
+// if it is empty
+func resolveChangelogTo(repo *git.Repository, component config.Component, to string) (*object.Commit, error) {
+	if to == "" {
+		return headCommit(repo)
+	}
+	h, err := resolveChangelogRef(repo, component, to)
+	if err != nil {
+		return nil, err
+	}
+	return repo.CommitObject(h)
+}
+
+// backfillReleases returns a release for every version tag in the whole history
+func backfillReleases(repo *git.Repository, component config.Component, nextVersion string) ([]changelog.Release, error) {
+	head, err := headCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+	return changelogReleases(repo, component, head, plumbing.ZeroHash, nextVersion)
+}
+
+// backfillChangelog regenerates the component's changelog with the releases, keeping its preamble, and the sections
+// of versions that are not released, such as pre-releases and the Unreleased section of a keepachangelog.com changelog
+func backfillChangelog(repo *git.Repository, component config.Component, releases []changelog.Release, nextVersion string) error {
+	content, err := ioutil.ReadFile(component.ChangelogFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "unable to read %s", component.ChangelogFile)
+	}
+	doc := changelog.Parse(string(content))
+	preamble := doc.Preamble
+	if len(preamble) == 0 {
+		preamble = []string{"# Changelog", ""}
+	}
+
+	released := map[string]bool{}
+	for _, release := range releases {
+		released[release.Version] = true
+	}
+	// the sections of released versions are regenerated, the link references are updated
+	kept := &changelog.Document{Preamble: preamble, Footer: doc.Footer}
+	for _, s := range doc.Sections {
+		if released[s.Version] {
+			continue
+		}
+		if !strings.EqualFold(s.Version, changelog.Unreleased) {
+			fmt.Printf("Keeping the %s section of %s, there is no version tag for it\n", s.Version, component.ChangelogFile)
+		}
+		kept.Upsert(s)
+	}
+
+	fmt.Printf("Regenerating %s changelog with %d releases\n", component.DisplayName(), len(releases))
+	if cfg.Changelog.Format == changelog.KeepAChangelog {
+		err = addKeepAChangelogReleases(repo, kept, component, releases, nextVersion)
 		if err != nil {
 			return err
 		}
-		fmt.Println("Done.")
-		return nil
-	},
+	} else {
+		for i := len(releases) - 1; i >= 0; i-- {
+			section, err := renderRelease(releases[i])
+			if err != nil {
+				return err
+			}
+			for _, s := range changelog.Parse(section).Sections {
+				kept.Upsert(s)
+			}
+		}
+	}
+	err = ioutil.WriteFile(component.ChangelogFile, []byte(kept.String()), 0644)
+	return errors.Wrapf(err, "unable to write %s", component.ChangelogFile)
 }
 
-// GetCommitLog takes a commit object and returns a commit log that may link to a pull request, for example:
-// * [2847a2e6](../../commit/2847a2e624ee6736b43cc3a68acd75368d1a75d1) A commit message
-// * [2847a2e6](../../commit/2847a2e624ee6736b43cc3a68acd75368d1a75d1) A commit message ([#100](../../pull/100))
-func GetCommitLog(commit *object.Commit) string {
-	hash := commit.Hash.String()
-	if hash != "" {
-		var commitLog string
-		commitMsg := strings.Split(commit.Message, "\n")[0]
-		shortHash := hash[:8]
-		r := regexp.MustCompile(`\(#\d+\)$`)
-		idx := r.FindStringIndex(commitMsg)
-		if idx == nil {
-			commitLog = fmt.Sprintf("* [%s](../../commit/%s) %s", shortHash, hash, commitMsg)
-		} else {
-			// extract message and PR number from commitMsg
-			message, prNum := commitMsg[:idx[0]], commitMsg[idx[0]+2:idx[1]-1]
+// changelogReleases returns a release per version tag in the history of to after from (excluded, the zero hash for
+// the whole history), dated by the tagged commit, with the commits touching the component since the previous tag.
+// Commits after the newest tag make up the release of nextVersion dated now, or are left out if it is empty.
+// Releases are newest first.
+func changelogReleases(repo *git.Repository, component config.Component, to *object.Commit, from plumbing.Hash, nextVersion string) ([]changelog.Release, error) {
+	tags, err := util.VersionTags(repo, component.TagPrefix, false)
+	if err != nil {
+		return nil, err
+	}
+	history, err := util.CommitsBetween(repo, to, from)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve commit history")
+	}
+
+	filter, err := changelogFilter()
+	if err != nil {
+		return nil, err
+	}
+	mm, err := mailmap.Read(".mailmap")
+	if err != nil {
+		return nil, err
+	}
+	issuePatterns, err := changelogIssuePatterns()
+	if err != nil {
+		return nil, err
+	}
+	links := repoForge(repo)
+	classifier := newClassifier()
+
+	// the changelog lists the commits touching the component that pass the filter, checked once per commit
+	keep := map[plumbing.Hash]bool{}
+	listed := func(commit *object.Commit) (bool, error) {
+		if kept, ok := keep[commit.Hash]; ok {
+			return kept, nil
+		}
+		// release commits of pre-releases only touch the version files and the changelog
+		paths, err := util.ChangedPaths(commit)
+		if err != nil {
+			return false, errors.Wrap(err, "error generating changelog")
+		}
+		keep[commit.Hash] = touchesComponent(paths, component) && filter.Keep(commit, paths)
+		return keep[commit.Hash], nil
+	}
+	// contributors are new in a release if they authored none of the commits reachable from the previous one
+	var seen map[string]bool
+	if cfg.Changelog.Attribution {
+		seen, err = contributorsBefore(repo, from, mm)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newRelease := func(version string, date time.Time) changelog.Release {
+		return changelog.Release{
+			Component:   component.Name,
+			Version:     version,
+			Date:        date,
+			Commits:     []changelog.Commit{},
+			Attribution: cfg.Changelog.Attribution,
+		}
+	}
+	// the version tags in the range, newest first, each ending the release of the commits since the previous one
+	tagged := []*object.Commit{}
+	for _, commit := range history {
+		if _, ok := tags[commit.Hash]; ok {
+			tagged = append(tagged, commit)
+		}
+	}
+	sort.SliceStable(tagged, func(i, j int) bool {
+		return tags[tagged[i].Hash].GT(tags[tagged[j].Hash])
+	})
+	releases, heads := []changelog.Release{}, []*object.Commit{}
+	if nextVersion != "" {
+		releases = append(releases, newRelease(nextVersion, time.Now()))
+		heads = append(heads, to)
+	}
+	for _, commit := range tagged {
+		releases = append(releases, newRelease(tags[commit.Hash].String(), commit.Committer.When))
+		heads = append(heads, commit)
+	}
 
-			commitLog = fmt.Sprintf("* [%s](../../commit/%s) %s([#%s](../../pull/%s))", shortHash, hash, message, prNum, prNum)
+	// a release has the commits reachable from its tag but not from the previous one, like git log previous..tag,
+	// so that the commits of a branch merged after a release are not listed in it. Releases are walked oldest
+	// first, so that the contributors seen before a release are known.
+	for i := len(releases) - 1; i >= 0; i-- {
+		since := from
+		if i+1 < len(heads) {
+			since = heads[i+1].Hash
 		}
-		return commitLog
+		commits, err := util.CommitsBetween(repo, heads[i], since)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve the commits of %s", releases[i].Version)
+		}
+		credited := map[string]bool{}
+		for _, commit := range commits {
+			ok, err := listed(commit)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			c := changelog.NewCommit(commit, classifier, links, mm)
+			c.LinkIssues(issuePatterns)
+			releases[i].Commits = append(releases[i].Commits, c)
+			if !cfg.Changelog.Attribution {
+				continue
+			}
+			for _, contributor := range c.Contributors() {
+				if !seen[contributor.Key()] && !credited[contributor.Key()] {
+					credited[contributor.Key()] = true
+					releases[i].NewContributors = append(releases[i].NewContributors, contributor)
+				}
+			}
+		}
+		if cfg.Changelog.Attribution {
+			// every commit counts as a contribution, whether it is listed or not
+			for _, commit := range commits {
+				addContributors(seen, commit, mm)
+			}
+		}
+	}
+
+	if nextVersion == "" {
+		since := from
+		if len(tagged) > 0 {
+			since = tagged[0].Hash
+		}
+		pending, err := util.CommitsBetween(repo, to, since)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve the commits after the last release")
+		}
+		unreleased := 0
+		for _, commit := range pending {
+			ok, err := listed(commit)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				unreleased++
+			}
+		}
+		if unreleased > 0 {
+			logrus.Infof("Leaving out %d commits after the last release, pass the next version to add them", unreleased)
+		}
+	}
+
+	for i := range releases {
+		releases[i].ClosedIssues = changelog.ClosedIssues(releases[i].Commits)
+		if i+1 < len(releases) {
+			releases[i].PreviousVersion = releases[i+1].Version
+		} else if v, ok := tags[from]; ok {
+			releases[i].PreviousVersion = v.String()
+		}
+		if releases[i].PreviousVersion != "" {
+			releases[i].PreviousTag = component.TagName(releases[i].PreviousVersion)
+			releases[i].CompareURL = links.CompareURL(releases[i].PreviousTag, component.TagName(releases[i].Version))
+		} else {
+			// everyone is new in the first release
+			releases[i].NewContributors = nil
+		}
+	}
+	return releases, nil
+}
+
+// untaggedVersion returns nextVersion if it is not tagged yet. If it is the tag of to (HEAD if empty), its release
+// is one of the tagged ones and the empty version is returned. It is an error if it is the tag of another commit.
+func untaggedVersion(repo *git.Repository, component config.Component, to string, nextVersion string) (string, error) {
+	if nextVersion == "" {
+		return "", nil
+	}
+	toCommit, err := resolveChangelogTo(repo, component, to)
+	if err != nil {
+		return "", err
+	}
+	tagName := component.TagName(nextVersion)
+	tag, err := repo.Tag(tagName)
+	if err == git.ErrTagNotFound {
+		return nextVersion, nil
 	}
-	return ""
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to look up tag %s", tagName)
+	}
+	hash, err := util.TagCommitHash(repo, tag)
+	if err != nil {
+		return "", err
+	}
+	if hash != toCommit.Hash {
+		return "", errors.Errorf("%s is already tagged %s on commit %s, please give the next version", nextVersion, tagName, hash.String()[:8])
+	}
+	logrus.Infof("%s is already tagged %s, leaving out the next version", nextVersion, tagName)
+	return "", nil
 }
 
-// UpdateChangeLogFile writes the changelog content of the new version to CHANGELOG.md
-func UpdateChangeLogFile(newContent string) error {
-	filePath := "CHANGELOG.md"
-	f, err := os.OpenFile(filePath, syscall.O_RDWR|syscall.O_CREAT, 0644)
+// contributorsBefore returns the keys of the contributors of every commit reachable from from, none if it is the
+// zero hash. Contributors are authors and co-authors, resolved in the mailmap.
+func contributorsBefore(repo *git.Repository, from plumbing.Hash, mm *mailmap.Mailmap) (map[string]bool, error) {
+	seen := map[string]bool{}
+	if from == plumbing.ZeroHash {
+		return seen, nil
+	}
+	fromCommit, err := repo.CommitObject(from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find commit %s", from)
+	}
+	history, err := util.CommitsBetween(repo, fromCommit, plumbing.ZeroHash)
 	if err != nil {
-		return errors.Wrap(err, "unable to open CHANGELOG.md")
+		return nil, errors.Wrap(err, "failed to retrieve commit history")
+	}
+	for _, commit := range history {
+		addContributors(seen, commit, mm)
+	}
+	return seen, nil
+}
+
+// addContributors adds the keys of the commit's contributors to seen
+func addContributors(seen map[string]bool, commit *object.Commit, mm *mailmap.Mailmap) {
+	for _, contributor := range changelog.NewCommit(commit, commits.Classifier{}, forge.Forge{}, mm).Contributors() {
+		seen[contributor.Key()] = true
+	}
+}
+
+// changelogFilter returns the filter of the commits listed in the changelog, from the include and exclude rules
+func changelogFilter() (changelog.Filter, error) {
+	var err error
+	filter := changelog.Filter{}
+	filter.Include, err = changelogRules("changelog.include", cfg.Changelog.Include)
+	if err != nil {
+		return filter, err
+	}
+	filter.Exclude, err = changelogRules("changelog.exclude", cfg.Changelog.Exclude)
+	return filter, err
+}
+
+// changelogIssuePatterns returns the configured patterns of issue references
+func changelogIssuePatterns() ([]changelog.IssuePattern, error) {
+	patterns := []changelog.IssuePattern{}
+	for i, p := range cfg.Changelog.Issues {
+		if p.Pattern == "" || p.URL == "" {
+			return nil, errors.Errorf("changelog.issues pattern %d must set pattern and url", i+1)
+		}
+		pattern, err := changelog.NewIssuePattern(p.Pattern, p.URL)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+func changelogRules(key string, rules []config.CommitRule) ([]changelog.Rule, error) {
+	compiled := []changelog.Rule{}
+	for i, r := range rules {
+		if r.Subject == "" && r.Author == "" && r.Merge == nil && len(r.Paths) == 0 {
+			return nil, errors.Errorf("%s rule %d is empty, it must set subject, author, merge or paths", key, i+1)
+		}
+		rule := changelog.Rule{Merge: r.Merge, Paths: r.Paths}
+		var err error
+		if r.Subject != "" {
+			rule.Subject, err = regexp.Compile(r.Subject)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid subject in %s rule %d", key, i+1)
+			}
+		}
+		if r.Author != "" {
+			rule.Author, err = regexp.Compile(r.Author)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid author in %s rule %d", key, i+1)
+			}
+		}
+		compiled = append(compiled, rule)
 	}
-	defer f.Close()
-	scanner := bufio.NewScanner(f)
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	return compiled, nil
+}
+
+// printReleases prints the releases to stdout, as changelog sections or as a json or yaml list. The releases of
+// a component are consecutive.
+func printReleases(format string, releases []changelog.Release) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return errors.Wrap(encoder.Encode(releases), "unable to encode releases")
+	case "yaml":
+		out, err := yaml.Marshal(releases)
+		if err != nil {
+			return errors.Wrap(err, "unable to encode releases")
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	default:
+		// the sections of several components are each headed by their component's name
+		components := map[string]bool{}
+		for _, release := range releases {
+			components[release.Component] = true
+		}
+		sections := []string{}
+		for i, release := range releases {
+			section, err := renderRelease(release)
+			if err != nil {
+				return err
+			}
+			if len(components) > 1 && (i == 0 || releases[i-1].Component != release.Component) {
+				section = fmt.Sprintf("# %s\n\n%s", release.Component, section)
+			}
+			sections = append(sections, section)
+		}
+		fmt.Print(strings.Join(sections, "\n"))
+		return nil
 	}
-	if err := scanner.Err(); err != nil {
-		return errors.Wrap(err, "unable to read CHANGELOG.md")
+}
+
+// writeReleases adds a section per release to the component's changelog, releases are newest first
+func writeReleases(repo *git.Repository, component config.Component, releases []changelog.Release, nextVersion string) error {
+	if cfg.Changelog.Format == changelog.KeepAChangelog {
+		return writeKeepAChangelog(repo, component, releases, nextVersion)
+	}
+	for i := len(releases) - 1; i >= 0; i-- {
+		releaseLog, err := renderRelease(releases[i])
+		if err != nil {
+			return err
+		}
+		err = UpdateChangeLogFile(component.ChangelogFile, releaseLog)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeKeepAChangelog adds a section per release to the component's keepachangelog.com changelog, releases are
+// newest first. The Unreleased section is released as nextVersion, and the link references of the releases and of
+// Unreleased are updated.
+func writeKeepAChangelog(repo *git.Repository, component config.Component, releases []changelog.Release, nextVersion string) error {
+	content, err := ioutil.ReadFile(component.ChangelogFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "unable to read %s", component.ChangelogFile)
+	}
+	doc := changelog.Parse(string(content))
+	err = addKeepAChangelogReleases(repo, doc, component, releases, nextVersion)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(component.ChangelogFile, []byte(doc.String()), 0644)
+	return errors.Wrapf(err, "unable to edit %s", component.ChangelogFile)
+}
+
+// addKeepAChangelogReleases adds the sections and link references of the releases, newest first, to doc
+func addKeepAChangelogReleases(repo *git.Repository, doc *changelog.Document, component config.Component, releases []changelog.Release, nextVersion string) error {
+	links := repoForge(repo)
+	for i := len(releases) - 1; i >= 0; i-- {
+		release := releases[i]
+		releaseLog, err := renderRelease(release)
+		if err != nil {
+			return err
+		}
+		sections := changelog.Parse(releaseLog).Sections
+		if len(sections) != 1 {
+			return errors.Errorf("the changelog template must render a single \"## <version>\" section, got %q", releaseLog)
+		}
+
+		tag := component.TagName(release.Version)
+		var replaced bool
+		if release.Version == nextVersion {
+			replaced = doc.ReleaseUnreleased(sections[0])
+			if url := links.CompareURL(tag, "HEAD"); url != "" {
+				doc.SetLink(changelog.Unreleased, url)
+			}
+		} else {
+			replaced = doc.Upsert(sections[0])
+		}
+		if replaced {
+			fmt.Printf("Replacing the existing %s section of %s\n", release.Version, component.ChangelogFile)
+		}
+
+		url := release.CompareURL
+		if release.PreviousVersion == "" {
+			url = links.TagURL(tag)
+		}
+		if url != "" {
+			doc.SetLink(release.Version, url)
+		}
 	}
+	return nil
+}
 
-	// Insert new content to the second line of the existing content
-	updatedChangeLog := GetNewChangeLog(lines, newContent, 2)
+// resolveChangelogRef returns the commit of a tag, with or without the component's tag prefix, or of a revision
+func resolveChangelogRef(repo *git.Repository, component config.Component, ref string) (plumbing.Hash, error) {
+	for _, rev := range []string{component.TagName(ref), ref} {
+		h, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err == nil {
+			return *h, nil
+		}
+	}
+	return plumbing.ZeroHash, errors.Errorf("unable to find %s or %s", component.TagName(ref), ref)
+}
 
-	// Delete the existing changelog, and write the updated changelog
-	err = f.Truncate(0)
+// headCommit returns the commit HEAD points to
+func headCommit(repo *git.Repository) (*object.Commit, error) {
+	ref, err := repo.Head()
 	if err != nil {
-		return errors.Wrap(err, "unable to truncate existing CHANGELOG.md")
+		return nil, errors.Wrap(err, "could not get HEAD commit hash")
 	}
-	_, err = f.Seek(0, 0)
+	return repo.CommitObject(ref.Hash())
+}
+
+// repoForge returns the forge to link to, from the config or else the remote's URL. If the forge is
+// unknown links are relative to the repo's root on GitHub.
+func repoForge(repo *git.Repository) forge.Forge {
+	if cfg.Forge.URL != "" {
+		f, err := forge.New(cfg.Forge.URL, cfg.Forge.Type)
+		if err != nil {
+			logrus.WithError(err).Warn("invalid forge config, using relative links")
+		}
+		return f
+	}
+
+	remote, err := repo.Remote(cfg.Remote)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		logrus.Debugf("no %s remote, using relative links", cfg.Remote)
+		return forge.Forge{}
+	}
+	f, err := forge.FromRemote(remote.Config().URLs[0], cfg.Forge.Type)
 	if err != nil {
-		return errors.Wrap(err, "unable to go to start of CHANGELOG.md")
+		logrus.WithError(err).Debug("unknown forge, using relative links")
 	}
-	_, err = f.WriteString(updatedChangeLog)
-	return errors.Wrap(err, "unable to edit CHANGELOG.md")
+	return f
 }
 
-// GetNewChangeLog inserts new content just before the index'th line and returns all content as string
-// Negative index is treated as zero index (insert the new content to the beginning of the existing content)
-// If index is greater than the length of existing content is treated as inserting to the last line of the existing
-// content
-func GetNewChangeLog(lines []string, newContent string, index int) string {
-	// index must be between 0 and the number of existing lines
-	index = int(math.Max(float64(index), 0.0))
-	index = int(math.Min(float64(index), float64(len(lines))))
+// renderRelease renders the changelog section of a release with the configured template, or the default one
+// of the changelog format
+func renderRelease(release changelog.Release) (string, error) {
+	var text string
+	switch cfg.Changelog.Format {
+	case "":
+		text = changelog.DefaultTemplate
+	case changelog.KeepAChangelog:
+		text = changelog.KeepAChangelogTemplate
+	default:
+		return "", errors.Errorf("unknown changelog.format %s, must be %s or empty", cfg.Changelog.Format, changelog.KeepAChangelog)
+	}
+	if cfg.Changelog.Template != "" {
+		content, err := ioutil.ReadFile(cfg.Changelog.Template)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to read changelog template %s", cfg.Changelog.Template)
+		}
+		text = string(content)
+	}
+	return changelog.Render(text, release)
+}
 
-	lines = append(lines, "")
-	copy(lines[index+1:], lines[index:])
-	lines[index] = newContent
+// UpdateChangeLogFile writes the changelog section of the new version to the changelog file at filePath. The section
+// is inserted above the newest release it is newer than, or replaces the section of the same version if there is one,
+// so that running it again does not duplicate the section.
+func UpdateChangeLogFile(filePath string, newContent string) error {
+	sections := changelog.Parse(newContent).Sections
+	if len(sections) == 0 {
+		return errors.Errorf("the new changelog section must start with a \"## <version>\" heading, got %q", newContent)
+	}
 
-	fileContent := strings.Builder{}
-	for _, line := range lines {
-		fileContent.WriteString(line)
-		fileContent.WriteByte('\n')
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "unable to read %s", filePath)
+	}
+	doc := changelog.Parse(string(content))
+	for _, section := range sections {
+		if doc.Upsert(section) {
+			fmt.Printf("Replacing the existing %s section of %s\n", section.Version, filePath)
+		}
 	}
 
-	return fileContent.String()
+	err = ioutil.WriteFile(filePath, []byte(doc.String()), 0644)
+	return errors.Wrapf(err, "unable to edit %s", filePath)
 }
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+const componentsConfig = `default_branch: master
+components:
+  - name: client
+    paths: [client]
+  - name: api
+    paths: [api]
+changelog:
+  attribution: true
+  exclude:
+    - author: 'dependabot\[bot\]'
+    - subject: '^typo'
+`
+
+func TestChangelogNewContributors(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	r.commitBy("Alice", "alice@example.com", "client: first", map[string]string{
+		"client/VERSION": "1.0.0", "client/main.js": "1", "api/VERSION": "1.0.0", "api/main.go": "1",
+	})
+	// earlier commits count, even if they are not listed in the client changelog
+	r.commitBy("Dave", "dave@example.com", "api: before", map[string]string{"api/main.go": "0"})
+	released := r.commitBy("Erin", "erin@example.com", "typo in the client", map[string]string{"client/main.js": "0"})
+	r.tag("client/v1.0.0", released)
+	r.tag("api/v1.0.0", released)
+	r.commitBy("Dave", "dave@example.com", "client: from dave", map[string]string{"client/dave.js": "1"})
+	r.commitBy("Erin", "erin@example.com", "client: from erin", map[string]string{"client/erin.js": "1"})
+	r.commitBy("Bob", "bob@example.com", "api: only the api", map[string]string{"api/main.go": "2"})
+	r.commitBy("dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", "chore(deps): bump left-pad",
+		map[string]string{"client/package.json": "{}"})
+	r.commitBy("Carol", "carol@example.com", "client: second", map[string]string{"client/main.js": "2"})
+	r.commitBy("Bob", "bob@example.com", "client: third", map[string]string{"client/main.js": "3"})
+	r.push()
+
+	a.NoError(r.run(componentsConfig, "changelog", "1.1.0", "-c", "client"))
+
+	doc := changelog.Parse(r.read("client/CHANGELOG.md"))
+	a.Len(doc.Sections, 1)
+	section := strings.Join(doc.Sections[0].Body, "\n")
+	a.NotContains(section, "dependabot")
+	a.NotContains(section, "only the api")
+	a.Contains(section, "from dave")
+	a.Contains(section, "from erin")
+	a.Contains(section, "### New Contributors\n\n* Bob\n* Carol\n")
+	a.NotContains(section, "* Dave")
+	a.NotContains(section, "* Erin")
+
+	a.NoError(r.run(componentsConfig, "changelog", "1.1.0", "-c", "api"))
+	doc = changelog.Parse(r.read("api/CHANGELOG.md"))
+	a.Equal([]string{"1.1.0"}, sectionVersions(doc))
+	a.Contains(strings.Join(doc.Sections[0].Body, "\n"), "### New Contributors\n\n* Bob\n")
+	a.NotContains(strings.Join(doc.Sections[0].Body, "\n"), "Carol")
+}
+
+func sectionVersions(doc *changelog.Document) []string {
+	versions := []string{}
+	for _, s := range doc.Sections {
+		versions = append(versions, s.Version)
+	}
+	return versions
+}
+
+func TestChangelogMergedBranch(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "1.0.0", "main.go": "package main"})
+	r.tag("v1.0.0", first)
+	r.checkout("feature", first)
+	feature := r.commit("[feature] branch feature", map[string]string{"feature.txt": "feature"})
+	r.checkout("master", plumbing.ZeroHash)
+	r.tag("v1.0.1", r.commit("fix: patch", map[string]string{"fix.txt": "fix"}))
+	r.tag("v1.1.0", r.merge("Merge branch feature", feature, map[string]string{"feature.txt": "feature"}))
+	r.push()
+
+	a.NoError(r.run("default_branch: master\n", "changelog", "--backfill"))
+
+	doc := changelog.Parse(r.read("CHANGELOG.md"))
+	a.Equal([]string{"1.1.0", "1.0.1", "1.0.0"}, sectionVersions(doc))
+	a.Contains(strings.Join(doc.Sections[0].Body, "\n"), "branch feature")
+	a.NotContains(strings.Join(doc.Sections[1].Body, "\n"), "branch feature")
+	a.Contains(strings.Join(doc.Sections[1].Body, "\n"), "fix: patch")
+	a.Contains(strings.Join(doc.Sections[2].Body, "\n"), "initial commit")
+}
+
+func TestChangelogTaggedNextVersion(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	r.tag("v0.1.0", r.commit("initial commit", map[string]string{"VERSION": "0.1.0", "main.go": "1"}))
+	r.tag("v0.2.0", r.commit("[feature] second", map[string]string{"main.go": "2"}))
+	r.commit("fix: third", map[string]string{"main.go": "3"})
+	r.push()
+
+	// the range ends with the tag of the next version, its section is the tagged release
+	a.NoError(r.run("default_branch: master\n", "changelog", "0.2.0", "--from", "v0.1.0", "--to", "v0.2.0"))
+	doc := changelog.Parse(r.read("CHANGELOG.md"))
+	a.Equal([]string{"0.2.0"}, sectionVersions(doc))
+	a.Contains(strings.Join(doc.Sections[0].Body, "\n"), "second")
+
+	// the commits after the tag cannot be released as a version that is already tagged
+	err := r.run("default_branch: master\n", "changelog", "0.2.0", "--from", "v0.1.0")
+	a.Error(err)
+	a.Contains(err.Error(), "already tagged")
+	a.Equal(doc.String(), r.read("CHANGELOG.md"))
+}
+
+func TestChangelogBackfillKeepsUntaggedSections(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	r.tag("v1.0.0", r.commit("initial commit", map[string]string{"VERSION": "1.0.0", "main.go": "1"}))
+	r.tag("v1.1.0-rc.1", r.commit("[feature] second", map[string]string{"main.go": "2"}))
+	r.commit("docs: changelog", map[string]string{"CHANGELOG.md": "# Changelog\n\nHand-written intro.\n\n" +
+		"## 1.1.0-rc.1 2020-01-01\n\n* second, by hand\n\n## 1.0.0 2020-01-01\n\n* edited by hand\n"})
+	r.push()
+
+	a.NoError(r.run("default_branch: master\n", "changelog", "--backfill"))
+
+	doc := changelog.Parse(r.read("CHANGELOG.md"))
+	a.Equal([]string{"# Changelog", "", "Hand-written intro.", ""}, doc.Preamble)
+	a.Equal([]string{"1.1.0-rc.1", "1.0.0"}, sectionVersions(doc))
+	a.Contains(strings.Join(doc.Sections[0].Body, "\n"), "second, by hand")
+	a.NotContains(strings.Join(doc.Sections[1].Body, "\n"), "edited by hand")
+	a.Contains(strings.Join(doc.Sections[1].Body, "\n"), "initial commit")
+}
+
+func TestChangelogStdoutGroup(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{
+		"client/VERSION": "1.1.0", "client/main.js": "1", "server/VERSION": "1.1.0", "server/main.go": "1",
+	})
+	r.tag("client/v1.1.0", first)
+	r.tag("server/v1.1.0", first)
+	r.commit("fix: client bug", map[string]string{"client/main.js": "2"})
+	r.commit("fix: server bug", map[string]string{"server/main.go": "2"})
+	r.push()
+
+	config := `default_branch: master
+components:
+  - name: client
+    paths: [client]
+  - name: server
+    paths: [server]
+groups:
+  - name: app
+    components: [client, server]
+`
+	var err error
+	out := captureStdout(t, func() {
+		err = r.run(config, "changelog", "-c", "client", "--stdout")
+	})
+	a.NoError(err)
+
+	clientAt, serverAt := strings.Index(out, "# client\n\n## 1.1.1"), strings.Index(out, "# server\n\n## 1.1.1")
+	a.True(clientAt >= 0 && serverAt > clientAt, out)
+	a.Contains(out[clientAt:serverAt], "client bug")
+	a.NotContains(out[clientAt:serverAt], "server bug")
+	a.Contains(out[serverAt:], "server bug")
+}
+
+// captureStdout returns what f prints to stdout
+func captureStdout(t *testing.T, f func()) string {
+	stdout := os.Stdout
+	read, write, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = write
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		content, _ := ioutil.ReadAll(read)
+		out <- string(content)
+	}()
+	f()
+	write.Close()
+	return <-out
+}
+
+func TestChangelogComputedVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		rc      bool
+		args    []string
+		version string
+	}{
+		{"next version", false, nil, "1.1.0"},
+		{"first pre-release", false, []string{"--pre", "rc"}, "1.1.0-rc.1"},
+		{"next pre-release", true, []string{"--pre", "rc"}, "1.1.0-rc.2"},
+		{"next pre-release on another channel", true, []string{"--pre", "beta"}, "1.1.0-beta.1"},
+		{"stable after a pre-release", true, nil, "1.1.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+			r := newTestRepo(t)
+
+			r.tag("v1.0.0", r.commit("initial commit", map[string]string{"VERSION": "1.0.0", "main.go": "1"}))
+			feature := r.commit("[feature] new flag", map[string]string{"main.go": "2"})
+			if test.rc {
+				r.tag("v1.1.0-rc.1", feature)
+			}
+			r.commit("fix: bug", map[string]string{"main.go": "3"})
+			r.push()
+
+			a.NoError(r.run("default_branch: master\n", append([]string{"changelog"}, test.args...)...))
+
+			doc := changelog.Parse(r.read("CHANGELOG.md"))
+			a.Equal([]string{test.version}, sectionVersions(doc))
+			a.Contains(strings.Join(doc.Sections[0].Body, "\n"), "new flag")
+			a.Contains(strings.Join(doc.Sections[0].Body, "\n"), "fix: bug")
+		})
+	}
+}
+
+func TestChangelogPreWithVersion(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	r.tag("v1.0.0", r.commit("initial commit", map[string]string{"VERSION": "1.0.0", "main.go": "1"}))
+	r.push()
+
+	err := r.run("default_branch: master\n", "changelog", "1.1.0", "--pre", "rc")
+	a.Error(err)
+	a.Contains(err.Error(), "--pre only applies")
+}
@@ -1,102 +1,71 @@
 package cmd_test
 
 import (
-	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/chanzuckerberg/bff/cmd"
-	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"github.com/stretchr/testify/assert"
 )
 
-func TestGetCommitLog(t *testing.T) {
-	tests := []struct {
-		name   string
-		commit object.Commit
-		want   string
-	}{
-		{"non-empty commit, one-line commit message",
-			object.Commit{
-				Hash:    [20]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
-				Message: "A commit message",
-			},
-			"* [00010203](../../commit/0001020304050607080900010203040506070809) A commit message",
-		},
-		{"non-empty commit, multiple line commit message",
-			object.Commit{
-				Hash:    [20]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
-				Message: "A commit message\nWith multiple lines\nLast line.",
-			},
-			"* [00010203](../../commit/0001020304050607080900010203040506070809) A commit message",
-		},
-		{"non-empty commit, one-line commit message with pull request number",
-			object.Commit{
-				Hash:    [20]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
-				Message: "A commit message (#100)",
-			},
-			"* [00010203](../../commit/0001020304050607080900010203040506070809) A commit message ([#100](../../pull/100))",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := cmd.GetCommitLog(&tt.commit); got != tt.want {
-				fmt.Println(got)
-				t.Errorf("GetCommitLog() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestGetNewChangeLog(t *testing.T) {
+func TestUpdateChangeLogFile(t *testing.T) {
 	tests := []struct {
 		name       string
-		lines      []string
+		existing   *string
 		newContent string
-		index      int
 		want       string
 	}{
-		{"Insert content just before the third line",
-			[]string{"Title", "First line", "Second line"},
-			"New content",
-			2,
-			"Title\nFirst line\nNew content\nSecond line\n",
-		},
-		{"Insert content to the end of the file (index is equal the number of existing lines)",
-			[]string{"Title", "First line", "Second line"},
-			"New content",
-			3,
-			"Title\nFirst line\nSecond line\nNew content\n",
+		{"create the changelog",
+			nil,
+			"## 1.0.0 2020-01-02\n\n* a fix\n",
+			"## 1.0.0 2020-01-02\n\n* a fix\n\n",
 		},
-		{"Append new content to file with very few lines",
-			[]string{"Title"},
-			"New content",
-			10,
-			"Title\nNew content\n",
+		{"insert above the newest release",
+			strPtr("# Changelog\n\n## 0.9.0 2020-01-01\n\n* old fix\n"),
+			"## 1.0.0 2020-01-02\n\n* a fix\n",
+			"# Changelog\n\n## 1.0.0 2020-01-02\n\n* a fix\n\n## 0.9.0 2020-01-01\n\n* old fix\n",
 		},
-		{"Insert content to empty file",
-			[]string{},
-			"New content",
-			2,
-			"New content\n",
+		{"insert between older and newer releases",
+			strPtr("# Changelog\n\n## 1.1.0 2020-01-03\n\n* newer fix\n\n## 0.9.0 2020-01-01\n\n* old fix\n"),
+			"## 1.0.0 2020-01-02\n\n* a fix\n",
+			"# Changelog\n\n## 1.1.0 2020-01-03\n\n* newer fix\n\n## 1.0.0 2020-01-02\n\n* a fix\n\n## 0.9.0 2020-01-01\n\n* old fix\n",
 		},
-		{"Negative index means insertion at the beginning",
-			[]string{"Title", "First line", "Second line"},
-			"New content",
-			-1,
-			"New content\nTitle\nFirst line\nSecond line\n",
-		},
-		{"Negative index and empty file",
-			[]string{},
-			"New content",
-			-1,
-			"New content\n",
+		{"replace the section of the same version",
+			strPtr("# Changelog\n\n## 1.0.0 2020-01-01\n\n* stale\n\n## 0.9.0 2020-01-01\n\n* old fix\n"),
+			"## 1.0.0 2020-01-02\n\n* a fix\n",
+			"# Changelog\n\n## 1.0.0 2020-01-02\n\n* a fix\n\n## 0.9.0 2020-01-01\n\n* old fix\n",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := cmd.GetNewChangeLog(tt.lines, tt.newContent, tt.index); got != tt.want {
-				fmt.Println(got)
-				t.Errorf("GetNewChangeLog() = %v, want %v", got, tt.want)
+			a := assert.New(t)
+			dir, err := ioutil.TempDir("", "bff")
+			a.NoError(err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "CHANGELOG.md")
+			if tt.existing != nil {
+				a.NoError(ioutil.WriteFile(path, []byte(*tt.existing), 0644))
 			}
+
+			a.NoError(cmd.UpdateChangeLogFile(path, tt.newContent))
+			got, err := ioutil.ReadFile(path)
+			a.NoError(err)
+			a.Equal(tt.want, string(got))
 		})
 	}
 }
+
+func TestUpdateChangeLogFileWithoutSection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = cmd.UpdateChangeLogFile(filepath.Join(dir, "CHANGELOG.md"), "* a fix\n")
+	assert.Error(t, err)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// addComponentFlags adds --component, and optionally --all, to commands that act on monorepo components
+func addComponentFlags(cmd *cobra.Command, all bool) {
+	cmd.Flags().StringP("component", "c", "", "Only act on the named component declared in the config")
+	if all {
+		cmd.Flags().Bool("all", false, "Act on every component with changes since its last release")
+	}
+}
+
+// releaseUnit is what gets released together: a single component, or every member of a fixed group
+type releaseUnit struct {
+	// Name is the component or group name, empty for the whole repo
+	Name       string
+	Components []config.Component
+}
+
+// targetUnits returns what to release given --component (a component or group name) or --all.
+// Components that belong to a group are always released with the whole group.
+// Without either flag, the whole repo is the only unit.
+func targetUnits(cmd *cobra.Command) ([]releaseUnit, bool, error) {
+	name, err := cmd.Flags().GetString("component")
+	if err != nil {
+		return nil, false, err
+	}
+	all := false
+	if cmd.Flags().Lookup("all") != nil {
+		all, err = cmd.Flags().GetBool("all")
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if name != "" && all {
+		return nil, false, errors.New("--component and --all are mutually exclusive")
+	}
+	if name == "" && !all {
+		return []releaseUnit{{Components: []config.Component{cfg.Root()}}}, false, nil
+	}
+
+	components, err := cfg.AllComponents()
+	if err != nil {
+		return nil, false, err
+	}
+	if all && len(components) == 0 {
+		return nil, false, errors.New("--all needs components declared in the config")
+	}
+
+	units := []releaseUnit{}
+	seen := map[string]bool{}
+	for _, component := range components {
+		unit := releaseUnit{Name: component.Name, Components: []config.Component{component}}
+		if group, ok := cfg.GroupOf(component.Name); ok {
+			unit, err = groupUnit(group)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		if seen[unit.Name] || !(all || name == unit.Name || name == component.Name) {
+			continue
+		}
+		seen[unit.Name] = true
+		units = append(units, unit)
+	}
+	if len(units) == 0 {
+		return nil, false, errors.Errorf("unknown component %s", name)
+	}
+	return units, all, nil
+}
+
+func groupUnit(group config.Group) (releaseUnit, error) {
+	unit := releaseUnit{Name: group.Name}
+	for _, name := range group.Components {
+		component, err := cfg.Component(name)
+		if err != nil {
+			return unit, err
+		}
+		unit.Components = append(unit.Components, component)
+	}
+	return unit, nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Exit codes, so that CI pipelines can branch on the outcome of a command
+const (
+	// ExitReleased means the command succeeded, for bump a release was made (or would be, with --dry-run)
+	ExitReleased = 0
+	// ExitError means the command failed
+	ExitError = 1
+	// ExitNothingToRelease means there are no commits since the last release
+	ExitNothingToRelease = 2
+)
+
+// ErrNothingToRelease is returned when there are no commits since the last release
+var ErrNothingToRelease = errors.New("nothing to release, no commits since the last release")
+
+// ExitCode maps the error returned by a command to the process exit code
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitReleased
+	case errors.Is(err, ErrNothingToRelease):
+		return ExitNothingToRelease
+	default:
+		return ExitError
+	}
+}
@@ -0,0 +1,28 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/cmd"
+	"github.com/pkg/errors"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"released", nil, cmd.ExitReleased},
+		{"nothing to release", cmd.ErrNothingToRelease, cmd.ExitNothingToRelease},
+		{"wrapped nothing to release", errors.Wrap(cmd.ErrNothingToRelease, "component api"), cmd.ExitNothingToRelease},
+		{"error", errors.New("unable to fetch"), cmd.ExitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cmd.ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
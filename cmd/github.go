@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/chanzuckerberg/bff/pkg/forge"
+	"github.com/chanzuckerberg/bff/pkg/github"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	rootCmd.AddCommand(githubReleaseCmd)
+
+	addComponentFlags(githubReleaseCmd, false)
+}
+
+var githubReleaseCmd = &cobra.Command{
+	Use:   "github-release [version]",
+	Short: "Publish the GitHub release of a version's tag",
+	Long: fmt.Sprintf(`Publish the GitHub release of a version's tag, with the version's changelog section as its notes.
+
+The version defaults to the one in the version file. The release is created, or updated if there is one for
+the tag already, and marked as a pre-release for pre-release versions. The tag must have been pushed.
+The token is read from %s.`, config.GitHubTokenEnv),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := cmd.Flags().GetString("component")
+		if err != nil {
+			return err
+		}
+		component := cfg.Root()
+		if name != "" {
+			component, err = cfg.Component(name)
+			if err != nil {
+				return err
+			}
+		}
+
+		version := ""
+		if len(args) == 1 {
+			version = strings.TrimPrefix(args[0], component.TagPrefix)
+		} else {
+			version, err = readVersionFile(component.VersionFile)
+			if err != nil {
+				return errors.Wrapf(err, "unable to read the version from %s", component.VersionFile)
+			}
+		}
+		notes, err := sectionNotes(component, version)
+		if err != nil {
+			return err
+		}
+
+		repo, err := git.PlainOpen(".")
+		if err != nil {
+			return errors.Wrap(err, "could not open git repo")
+		}
+		publisher, err := newGitHubPublisher(repo)
+		if err != nil {
+			return err
+		}
+		return publisher.publish(component, version, notes)
+	},
+}
+
+// githubPublisher publishes the GitHub releases of the repo. Like the releaseTagger, it is set up before anything
+// is written, so that a missing token fails the release early.
+type githubPublisher struct {
+	client *github.Client
+	owner  string
+	name   string
+}
+
+func newGitHubPublisher(repo *git.Repository) (*githubPublisher, error) {
+	f := repoForge(repo)
+	if f.Type != forge.GitHub {
+		return nil, errors.New("GitHub releases need a repo on GitHub, please configure forge.url and forge.type")
+	}
+	token := os.Getenv(config.GitHubTokenEnv)
+	if token == "" {
+		return nil, errors.Errorf("%s must be set to publish GitHub releases", config.GitHubTokenEnv)
+	}
+
+	apiURL := cfg.GitHub.APIURL
+	if apiURL == "" {
+		var err error
+		apiURL, err = github.APIURL(f.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	owner, name, err := github.Repo(f.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &githubPublisher{client: github.NewClient(apiURL, token), owner: owner, name: name}, nil
+}
+
+// publish creates or updates the GitHub release of the component's version tag with notes
+func (p *githubPublisher) publish(component config.Component, version string, notes string) error {
+	tag := component.TagName(version)
+	v, parseErr := semver.ParseTolerant(version)
+	release, err := p.client.PublishRelease(p.owner, p.name, github.Release{
+		TagName:    tag,
+		Name:       tag,
+		Body:       notes,
+		Prerelease: parseErr == nil && len(v.Pre) > 0,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Published GitHub release %s\n", release.HTMLURL)
+	return nil
+}
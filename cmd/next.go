@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	rootCmd.AddCommand(nextCmd)
+
+	nextCmd.Flags().StringP("output", "o", "plain", "Output format, plain or json")
+	nextCmd.Flags().String("pre", "", "Compute a pre-release on the given channel, e.g. alpha, beta or rc")
+	addComponentFlags(nextCmd, false)
+}
+
+var nextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Print the next version based on git history since last version, without changing anything",
+	Long: fmt.Sprintf(`Print the next version based on git history since last version, without changing anything.
+Unlike bump, next does not fetch, so the result is based on the local view of the default branch.
+
+Exit codes: %d success, %d error, %d nothing to release.`,
+		ExitReleased, ExitError, ExitNothingToRelease),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		if output != "plain" && output != "json" {
+			return errors.Errorf("unknown output format %s, must be plain or json", output)
+		}
+		pre, err := cmd.Flags().GetString("pre")
+		if err != nil {
+			return err
+		}
+
+		units, _, err := targetUnits(cmd)
+		if err != nil {
+			return err
+		}
+
+		repo, err := git.PlainOpen(".")
+		if err != nil {
+			return errors.Wrap(err, "could not open git repo")
+		}
+		branchRef, err := defaultBranchRef()
+		if err != nil {
+			return err
+		}
+		plans, err := planUnit(repo, branchRef, units[0], pre)
+		if err != nil {
+			return err
+		}
+		// the members of a group share one version
+		plan := plans[0]
+
+		current, releaseType, next := plan.CurrentVersion(), plan.ReleaseType, plan.Next
+		if nothingToRelease(plans) {
+			releaseType, next = "none", current
+		}
+
+		if output == "json" {
+			err = json.NewEncoder(os.Stdout).Encode(struct {
+				CurrentVersion string `json:"current_version"`
+				ReleaseType    string `json:"release_type"`
+				NextVersion    string `json:"next_version"`
+			}{current.String(), releaseType, next.String()})
+			if err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("current version is: %s\n", current)
+			fmt.Printf("release type is: %s\n", releaseType)
+			fmt.Printf("next version is: %s\n", next)
+		}
+
+		if nothingToRelease(plans) {
+			return ErrNothingToRelease
+		}
+		return nil
+	},
+}
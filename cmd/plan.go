@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"path"
+
+	"github.com/blang/semver"
+	"github.com/chanzuckerberg/bff/pkg/commits"
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/chanzuckerberg/bff/pkg/util"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// releasePlan is the next release of a component, computed from the history of the default branch since its latest stable version tag
+type releasePlan struct {
+	Component config.Component
+	// Tag is the latest stable version tag, empty if there is none yet
+	Tag     string
+	TagHash plumbing.Hash
+	// LatestTag is the latest version tag including pre-releases, empty if there is none yet
+	LatestTag     string
+	LatestTagHash plumbing.Hash
+	// Head is the default branch's commit
+	Head *object.Commit
+	// Current is the latest stable version and Latest the latest version including pre-releases
+	Current semver.Version
+	Latest  semver.Version
+	// Breaking and Feature are true if any of the commits are breaking changes or features
+	Breaking    bool
+	Feature     bool
+	ReleaseType string
+	// Pre is the pre-release channel, empty for stable releases
+	Pre  string
+	Next semver.Version
+	// Commits are the commits since the latest stable version tag, newest first
+	Commits []*object.Commit
+	// Unreleased are the commits since the latest version tag including pre-releases, newest first
+	Unreleased []*object.Commit
+}
+
+// NothingToRelease reports whether there are no commits since the latest release of the planned kind
+func (p *releasePlan) NothingToRelease() bool {
+	if p.Pre != "" {
+		return len(p.Unreleased) == 0
+	}
+	return len(p.Commits) == 0
+}
+
+// CurrentVersion is the version the next version follows: the latest version including
+// pre-releases when planning a pre-release, the latest stable version otherwise
+func (p *releasePlan) CurrentVersion() semver.Version {
+	if p.Pre != "" {
+		return p.Latest
+	}
+	return p.Current
+}
+
+// planUnit computes the next release of every component of the unit. A single component's next version
+// follows from its own history, the members of a fixed group share the version computed by planLockstep.
+func planUnit(repo *git.Repository, branchRef string, unit releaseUnit, pre string) ([]*releasePlan, error) {
+	plans := []*releasePlan{}
+	for _, component := range unit.Components {
+		plan, err := planHistory(repo, branchRef, component)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, planLockstep(repo, plans, pre)
+}
+
+// nothingToRelease reports whether none of the plans of a unit has anything to release
+func nothingToRelease(plans []*releasePlan) bool {
+	for _, plan := range plans {
+		if !plan.NothingToRelease() {
+			return false
+		}
+	}
+	return true
+}
+
+// planHistory finds the latest version tags of component on branchRef, and classifies the commits
+// touching the component since its latest stable version tag
+func planHistory(repo *git.Repository, branchRef string, component config.Component) (*releasePlan, error) {
+	head, err := util.VerifyDefaultBranch(repo, branchRef)
+	if err != nil {
+		return nil, err
+	}
+	latestVersionTag, latestVersionHash, err := util.LatestTagCommitHash(repo, branchRef, component.TagPrefix)
+	if err != nil {
+		return nil, err
+	}
+	latestTag, latestTagHash, err := util.LatestTagCommitHashWithPreReleases(repo, branchRef, component.TagPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &releasePlan{Component: component, Head: head, TagHash: *latestVersionHash, LatestTagHash: *latestTagHash}
+	if latestVersionTag != nil {
+		plan.Tag = *latestVersionTag
+	}
+	if latestTag != nil {
+		plan.LatestTag = *latestTag
+	}
+
+	plan.Commits, err = releasableCommitsSince(repo, head, plan.TagHash, component)
+	if err != nil {
+		return nil, err
+	}
+	plan.Unreleased, err = releasableCommitsSince(repo, head, plan.LatestTagHash, component)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier := newClassifier()
+	for _, commit := range plan.Commits {
+		switch classifier.Classify(commit.Message) {
+		case commits.Breaking:
+			plan.Breaking = true
+		case commits.Feature:
+			plan.Feature = true
+		}
+	}
+
+	// without a version tag, start from the initial version
+	current := plan.Tag
+	if current == "" {
+		current = initialVersion
+	}
+	plan.Current, err = semver.Make(current)
+	if err != nil {
+		return nil, err
+	}
+	plan.Latest = plan.Current
+	if plan.LatestTag != "" {
+		plan.Latest, err = semver.Make(plan.LatestTag)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// planLockstep sets one next version for all plans: the release type is the biggest across all of them, applied
+// to the highest current version. With a pre-release channel, the next version is the next stable version with a
+// channel.N suffix, numbered after the highest existing pre-release of any of them.
+func planLockstep(repo *git.Repository, plans []*releasePlan, pre string) error {
+	current, latest := plans[0].Current, plans[0].Latest
+	breaking, feature := false, false
+	for _, plan := range plans {
+		if plan.Current.GT(current) {
+			current = plan.Current
+		}
+		if plan.Latest.GT(latest) {
+			latest = plan.Latest
+		}
+		breaking = breaking || plan.Breaking
+		feature = feature || plan.Feature
+	}
+
+	releaseType := ReleaseType(current.Major, breaking, feature)
+	next := NewVersion(current, releaseType)
+	if pre != "" {
+		var latestPre uint64
+		for _, plan := range plans {
+			n, err := util.LatestPreReleaseNumber(repo, plan.Component.TagPrefix, next, pre)
+			if err != nil {
+				return err
+			}
+			if n > latestPre {
+				latestPre = n
+			}
+		}
+		var err error
+		next, err = NewPreRelease(next, pre, latestPre)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, plan := range plans {
+		plan.Current, plan.Latest = current, latest
+		plan.ReleaseType, plan.Pre, plan.Next = releaseType, pre, next
+	}
+	return nil
+}
+
+// releasableCommitsSince returns the commits reachable from head but not from until that touch the component,
+// including the ones of merged branches, without the ones that only touch its version file or changelog, such as
+// the commit promote makes after tagging
+func releasableCommitsSince(repo *git.Repository, head *object.Commit, until plumbing.Hash, component config.Component) ([]*object.Commit, error) {
+	all, err := util.CommitsBetween(repo, head, until)
+	if err != nil {
+		return nil, err
+	}
+
+	releasable := []*object.Commit{}
+	for _, commit := range all {
+		paths, err := util.ChangedPaths(commit)
+		if err != nil {
+			return nil, err
+		}
+		if touchesComponent(paths, component) {
+			releasable = append(releasable, commit)
+		}
+	}
+	return releasable, nil
+}
+
+// touchesComponent reports whether any of the changed paths belong to the component, other than its
+// version files and changelog. Empty commits only belong to the whole repo.
+func touchesComponent(paths []string, component config.Component) bool {
+	if len(paths) == 0 {
+		return len(component.Paths) == 0
+	}
+	bookkeeping := map[string]bool{
+		path.Clean(component.VersionFile):   true,
+		path.Clean(component.ChangelogFile): true,
+	}
+	for _, f := range component.VersionFiles {
+		bookkeeping[path.Clean(f.Path)] = true
+	}
+	for _, p := range paths {
+		if bookkeeping[p] {
+			continue
+		}
+		if component.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// newClassifier returns the commit classifier with the configured markers
+func newClassifier() commits.Classifier {
+	return commits.Classifier{
+		BreakingMarkers: cfg.Markers.Breaking,
+		FeatureMarkers:  cfg.Markers.Feature,
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestPlanLockstep(t *testing.T) {
+	type member struct {
+		current  string
+		latest   string
+		breaking bool
+		feature  bool
+	}
+	tests := []struct {
+		name        string
+		members     []member
+		pre         string
+		tags        []string
+		current     string
+		latest      string
+		releaseType string
+		next        string
+	}{
+		{"one member", []member{{"1.2.0", "1.2.0", false, true}}, "", nil,
+			"1.2.0", "1.2.0", "minor", "1.3.0"},
+		{"biggest release type", []member{{"1.2.0", "1.2.0", false, true}, {"1.2.0", "1.2.0", true, false}}, "", nil,
+			"1.2.0", "1.2.0", "major", "2.0.0"},
+		{"members on different versions", []member{{"1.4.0", "1.4.0", false, false}, {"1.2.0", "1.2.0", false, true}}, "", nil,
+			"1.4.0", "1.4.0", "minor", "1.5.0"},
+		{"highest version first", []member{{"1.2.0", "1.2.0", false, false}, {"1.4.0", "1.4.0", false, false}}, "", nil,
+			"1.4.0", "1.4.0", "patch", "1.4.1"},
+		{"breaking before 1.0.0", []member{{"0.3.0", "0.3.0", false, false}, {"0.2.0", "0.2.0", true, false}}, "", nil,
+			"0.3.0", "0.3.0", "minor", "0.4.0"},
+		{"first pre-release", []member{{"1.2.0", "1.2.0", false, true}, {"1.2.0", "1.2.0", false, false}}, "rc", nil,
+			"1.2.0", "1.2.0", "minor", "1.3.0-rc.1"},
+		{"one member has an rc", []member{{"1.2.0", "1.2.0", false, true}, {"1.2.0", "1.3.0-rc.1", false, false}}, "rc",
+			[]string{"server/v1.3.0-rc.1"},
+			"1.2.0", "1.3.0-rc.1", "minor", "1.3.0-rc.2"},
+		{"rc numbers of all members", []member{{"1.2.0", "1.3.0-rc.3", false, true}, {"1.2.0", "1.3.0-rc.1", false, false}}, "rc",
+			[]string{"client/v1.3.0-rc.1", "client/v1.3.0-rc.2", "client/v1.3.0-rc.3", "server/v1.3.0-rc.1"},
+			"1.2.0", "1.3.0-rc.3", "minor", "1.3.0-rc.4"},
+		{"other channel", []member{{"1.2.0", "1.2.0", false, true}, {"1.2.0", "1.3.0-rc.1", false, false}}, "beta",
+			[]string{"server/v1.3.0-rc.1"},
+			"1.2.0", "1.3.0-rc.1", "minor", "1.3.0-beta.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+			r := newTestRepo(t)
+			head := r.commit("initial commit", map[string]string{"client/main.js": "1", "server/main.go": "1"})
+			for _, tag := range test.tags {
+				r.tag(tag, head)
+			}
+
+			plans := []*releasePlan{}
+			for i, m := range test.members {
+				name := []string{"client", "server"}[i]
+				plans = append(plans, &releasePlan{
+					Component: config.Component{Name: name, TagPrefix: name + "/v"},
+					Current:   semver.MustParse(m.current),
+					Latest:    semver.MustParse(m.latest),
+					Breaking:  m.breaking,
+					Feature:   m.feature,
+				})
+			}
+
+			a.NoError(planLockstep(r.repo, plans, test.pre))
+			for _, plan := range plans {
+				a.Equal(test.current, plan.Current.String())
+				a.Equal(test.latest, plan.Latest.String())
+				a.Equal(test.releaseType, plan.ReleaseType)
+				a.Equal(test.pre, plan.Pre)
+				a.Equal(test.next, plan.Next.String())
+			}
+		})
+	}
+}
+
+func TestPlanMergedBranch(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "1.0.0", "main.go": "1"})
+	r.tag("v1.0.0", first)
+	r.checkout("feature", first)
+	feature := r.commit("[feature] branch feature", map[string]string{"feature.go": "1"})
+	r.checkout("master", plumbing.ZeroHash)
+	// the fix is committed after the feature, so it is the merge's most recent parent
+	r.commit("fix: patch", map[string]string{"main.go": "2"})
+	r.merge("Merge branch feature", feature, map[string]string{"feature.go": "1"})
+	r.push()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = r.run("default_branch: master\n", "next", "-o", "json")
+	})
+	a.NoError(err)
+	a.JSONEq(`{"current_version": "1.0.0", "release_type": "minor", "next_version": "1.1.0"}`, out)
+
+	a.NoError(r.run("default_branch: master\n", "bump", "--yes"))
+	_, err = r.repo.Reference(plumbing.NewTagReferenceName("v1.1.0"), true)
+	a.NoError(err)
+	a.Equal("1.1.0", r.read("VERSION"))
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/chanzuckerberg/bff/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func init() {
+	rootCmd.AddCommand(promoteCmd)
+
+	promoteCmd.Flags().BoolP("yes", "y", false, "Do not prompt, assume yes")
+	promoteCmd.Flags().Bool("dry-run", false, "Print the proposed promotion without changing anything")
+	promoteCmd.Flags().Bool("allow-dirty", false, "Release even if the working directory has uncommitted changes")
+	addComponentFlags(promoteCmd, false)
+}
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote",
+	Short: "Promote the latest pre-release to a stable release",
+	Long: `Promote the latest pre-release to a stable release.
+
+The version file and the changelog, with the pre-release sections consolidated into one, are
+updated in a release commit on the default branch. The stable tag (e.g. v1.4.0 for v1.4.0-rc.3)
+is then created on the exact commit of the pre-release tag, which must be on the default branch.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assumeYes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+		allowDirty, err := cmd.Flags().GetBool("allow-dirty")
+		if err != nil {
+			return err
+		}
+
+		units, _, err := targetUnits(cmd)
+		if err != nil {
+			return err
+		}
+		unit := units[0]
+
+		repo, err := git.PlainOpen(".")
+		if err != nil {
+			return errors.Wrap(err, "could not open git repo")
+		}
+		err = fetchRemote(repo)
+		if err != nil {
+			return err
+		}
+		w, err := repo.Worktree()
+		if err != nil {
+			return errors.Wrap(err, "unable to open worktree")
+		}
+		err = checkClean(w, allowDirty || dryRun, assumeYes)
+		if err != nil {
+			return err
+		}
+
+		branchRef, err := defaultBranchRef()
+		if err != nil {
+			return err
+		}
+		head, err := util.VerifyDefaultBranch(repo, branchRef)
+		if err != nil {
+			return err
+		}
+
+		// the members of a group are promoted together, and must share one version
+		promotions := []promotion{}
+		for _, component := range unit.Components {
+			p, err := findPromotion(repo, branchRef, head, component)
+			if err != nil {
+				return err
+			}
+			if len(promotions) > 0 && !p.Stable.Equals(promotions[0].Stable) {
+				return errors.Errorf("the latest pre-releases of group %s do not match: %s is %s, %s is %s", unit.Name,
+					promotions[0].Component.Name, promotions[0].Pre, component.Name, p.Pre)
+			}
+			promotions = append(promotions, p)
+		}
+		stable := promotions[0].Stable
+
+		for _, p := range promotions {
+			if p.Component.Name != "" {
+				fmt.Printf("component: %s\n", p.Component.Name)
+			}
+			fmt.Printf("pre-release is: %s (%s)\n", p.Pre, p.Hash.String()[:8])
+			fmt.Printf("proposed version is: %s\n", p.Stable)
+		}
+		if dryRun {
+			return nil
+		}
+		tagger, err := newReleaseTagger(repo)
+		if err != nil {
+			return err
+		}
+		proceed, err := confirm("proceed?", assumeYes)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			logrus.Info("ok, quitting")
+			return nil
+		}
+
+		paths := []string{}
+		for _, p := range promotions {
+			written, err := writeVersionFiles(p.Component, stable.String())
+			if err != nil {
+				return err
+			}
+			paths = append(paths, written...)
+			consolidated, err := ConsolidateChangeLogFile(repo, branchRef, p.Component, stable)
+			if err != nil {
+				return err
+			}
+			if consolidated {
+				paths = append(paths, p.Component.ChangelogFile)
+			}
+		}
+		_, err = commitRelease(w, unit.Name, stable.String(), paths...)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range promotions {
+			notes, err := releaseNotes(p.Component, stable.String(), nil)
+			if err != nil {
+				return err
+			}
+			err = tagger.tag(p.Component, stable.String(), p.Hash, notes)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// promotion is the latest pre-release of a component and the stable version it graduates to
+type promotion struct {
+	Component config.Component
+	Pre       semver.Version
+	Stable    semver.Version
+	// Hash is the commit of the pre-release tag
+	Hash plumbing.Hash
+}
+
+// findPromotion finds the component's latest pre-release, and verifies that it is on the default branch and
+// that its stable version has not been released yet
+func findPromotion(repo *git.Repository, branchRef string, head *object.Commit, component config.Component) (promotion, error) {
+	p := promotion{Component: component}
+	pre, preHash, err := util.LatestPreReleaseTag(repo, component.TagPrefix)
+	if err != nil {
+		return p, err
+	}
+	if pre == nil {
+		return p, errors.Errorf("there is no %s pre-release to promote", component.DisplayName())
+	}
+	p.Pre, p.Hash = *pre, preHash
+	p.Stable = *pre
+	p.Stable.Pre = nil
+
+	_, err = repo.Tag(component.TagName(p.Stable.String()))
+	if err == nil {
+		return p, errors.Errorf("%s has already been released", component.TagName(p.Stable.String()))
+	}
+	if err != git.ErrTagNotFound {
+		return p, errors.Wrapf(err, "unable to look up tag %s", component.TagName(p.Stable.String()))
+	}
+
+	preCommit, err := repo.CommitObject(preHash)
+	if err != nil {
+		return p, errors.Wrapf(err, "unable to find the commit of %s", component.TagName(pre.String()))
+	}
+	onBranch, err := preCommit.IsAncestor(head)
+	if err != nil {
+		return p, errors.Wrapf(err, "unable to check that %s is on %s", component.TagName(pre.String()), branchRef)
+	}
+	if !onBranch {
+		return p, errors.Errorf("%s is not on %s, only pre-releases from the default branch can be promoted", component.TagName(pre.String()), branchRef)
+	}
+	return p, nil
+}
+
+// ConsolidateChangeLogFile replaces the pre-release sections of version in the component's changelog with a
+// single section for version, headed like the changelog's other releases. The link references of a
+// keepachangelog.com changelog are updated too. It returns false if there is no changelog or no pre-release sections.
+func ConsolidateChangeLogFile(repo *git.Repository, branchRef string, component config.Component, version semver.Version) (bool, error) {
+	filePath := component.ChangelogFile
+	content, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to read %s", filePath)
+	}
+
+	previous, _, err := util.LatestTagCommitHash(repo, branchRef, component.TagPrefix)
+	if err != nil {
+		return false, err
+	}
+	links := repoForge(repo)
+	tag := component.TagName(version.String())
+	release := changelog.Release{Component: component.Name, Version: version.String(), Date: time.Now(), Commits: []changelog.Commit{}}
+	if *previous != "" {
+		release.PreviousVersion = *previous
+		release.PreviousTag = component.TagName(*previous)
+		release.CompareURL = links.CompareURL(release.PreviousTag, tag)
+	}
+	releaseLog, err := renderRelease(release)
+	if err != nil {
+		return false, err
+	}
+	sections := changelog.Parse(releaseLog).Sections
+	if len(sections) != 1 {
+		return false, errors.Errorf("the changelog template must render a single \"## <version>\" section, got %q", releaseLog)
+	}
+
+	doc := changelog.Parse(string(content))
+	preReleases := []string{}
+	ok := doc.Consolidate(func(v string) bool {
+		sectionVersion, err := semver.Parse(v)
+		if err != nil || len(sectionVersion.Pre) == 0 {
+			return false
+		}
+		if sectionVersion.Major != version.Major || sectionVersion.Minor != version.Minor || sectionVersion.Patch != version.Patch {
+			return false
+		}
+		preReleases = append(preReleases, v)
+		return true
+	}, sections[0].Heading)
+	if !ok {
+		return false, nil
+	}
+
+	if cfg.Changelog.Format == changelog.KeepAChangelog {
+		for _, v := range preReleases {
+			doc.RemoveLink(v)
+		}
+		url := release.CompareURL
+		if release.PreviousVersion == "" {
+			url = links.TagURL(tag)
+		}
+		if url != "" {
+			doc.SetLink(release.Version, url)
+		}
+		if url := links.CompareURL(tag, "HEAD"); url != "" {
+			doc.SetLink(changelog.Unreleased, url)
+		}
+	}
+
+	err = ioutil.WriteFile(filePath, []byte(doc.String()), 0644)
+	return true, errors.Wrapf(err, "unable to edit %s", filePath)
+}
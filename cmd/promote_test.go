@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoteChangelog(t *testing.T) {
+	date := time.Now().Format("2006-01-02")
+	tests := []struct {
+		name      string
+		format    string
+		changelog string
+		heading   string
+		entries   []string
+		footer    []string
+	}{
+		{"default format", "", `# Changelog
+
+## 1.1.0-rc.2 2020-01-02
+
+### Fixes/Other
+
+* fix: second
+
+## 1.1.0-rc.1 2020-01-01
+
+### Features
+
+* [feature] first
+
+## 1.0.0 2019-12-01
+
+* initial commit
+`,
+			"## [1.1.0](https://github.com/org/repo/compare/v1.0.0...v1.1.0) " + date,
+			[]string{"### Features", "* [feature] first", "### Fixes/Other", "* fix: second"},
+			[]string{}},
+		{"keepachangelog", changelog.KeepAChangelog, `# Changelog
+
+## [Unreleased]
+
+## [1.1.0-rc.2] - 2020-01-02
+
+### Fixed
+
+- second
+
+## [1.1.0-rc.1] - 2020-01-01
+
+### Added
+
+- first
+
+## [1.0.0] - 2019-12-01
+
+### Added
+
+- initial commit
+
+[Unreleased]: https://github.com/org/repo/compare/v1.1.0-rc.2...HEAD
+[1.1.0-rc.2]: https://github.com/org/repo/compare/v1.1.0-rc.1...v1.1.0-rc.2
+[1.1.0-rc.1]: https://github.com/org/repo/compare/v1.0.0...v1.1.0-rc.1
+[1.0.0]: https://github.com/org/repo/releases/tag/v1.0.0
+`,
+			"## [1.1.0] - " + date,
+			[]string{"### Added", "- first", "### Fixed", "- second"},
+			[]string{
+				"[Unreleased]: https://github.com/org/repo/compare/v1.1.0...HEAD",
+				"[1.1.0]: https://github.com/org/repo/compare/v1.0.0...v1.1.0",
+				"[1.0.0]: https://github.com/org/repo/releases/tag/v1.0.0",
+			}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+			r := newTestRepo(t)
+
+			r.tag("v1.0.0", r.commit("initial commit", map[string]string{"VERSION": "1.0.0", "main.go": "1"}))
+			r.tag("v1.1.0-rc.1", r.commit("[feature] first", map[string]string{"main.go": "2"}))
+			r.tag("v1.1.0-rc.2", r.commit("fix: second", map[string]string{"main.go": "3", "CHANGELOG.md": test.changelog}))
+			r.push()
+
+			config := `default_branch: master
+forge:
+  url: https://github.com/org/repo
+changelog:
+  format: ` + test.format + "\n"
+			a.NoError(r.run(config, "promote", "--yes"))
+
+			doc := changelog.Parse(r.read("CHANGELOG.md"))
+			versions := sectionVersions(doc)
+			a.Equal("1.0.0", versions[len(versions)-1])
+			section := doc.Section("1.1.0")
+			if a.NotNil(section, versions) {
+				a.Equal(test.heading, section.Heading)
+				a.Equal(test.entries, section.Entries())
+			}
+			for _, v := range versions {
+				a.False(strings.HasPrefix(v, "1.1.0-"), versions)
+			}
+			a.Equal(test.footer, doc.Footer)
+		})
+	}
+}
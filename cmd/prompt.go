@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	prompt "github.com/segmentio/go-prompt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// isInteractive reports whether stdin is a terminal we can prompt on
+func isInteractive() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// confirm asks a yes/no question. With assumeYes the answer is always yes,
+// and when stdin is not a terminal it refuses to prompt instead of hanging.
+func confirm(question string, assumeYes bool) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+	if !isInteractive() {
+		return false, errors.Errorf("refusing to prompt (%s) because stdin is not a terminal, pass --yes to proceed", question)
+	}
+	return prompt.Confirm(question), nil
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	addBumpFlags(releaseCmd)
+}
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Bump the version and generate the changelog in a single release commit",
+	Long: fmt.Sprintf(`Bump the version and generate the changelog in a single release commit.
+
+The next version is computed like bump does. The changelog section for it is generated, every version
+file is updated, and both are committed together in one "release version X" commit, which is then tagged.
+
+Exit codes: %d released (or would release, with --dry-run), %d error, %d nothing to release.`,
+		ExitReleased, ExitError, ExitNothingToRelease),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBump(cmd, true)
+	},
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestRelease(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	r.tag("v1.0.0", r.commit("initial commit", map[string]string{
+		"VERSION": "1.0.0", "package.json": "{\n  \"version\": \"1.0.0\"\n}\n", "main.go": "1",
+	}))
+	head := r.commit("[feature] new flag", map[string]string{"main.go": "2"})
+	r.push()
+
+	config := `default_branch: master
+version_files:
+  - path: package.json
+`
+	a.NoError(r.run(config, "release", "--yes"))
+
+	tag, err := r.repo.Reference(plumbing.NewTagReferenceName("v1.1.0"), true)
+	a.NoError(err)
+	release, err := r.repo.CommitObject(tag.Hash())
+	a.NoError(err)
+	a.Equal("release version 1.1.0", release.Message)
+	a.Equal([]plumbing.Hash{head}, release.ParentHashes)
+
+	// the version files and the changelog are released together in the tagged commit
+	paths, err := util.ChangedPaths(release)
+	a.NoError(err)
+	a.ElementsMatch([]string{"VERSION", "package.json", "CHANGELOG.md"}, paths)
+	tree, err := release.Tree()
+	a.NoError(err)
+	files := map[string]string{}
+	for _, path := range paths {
+		f, err := tree.File(path)
+		a.NoError(err)
+		files[path], err = f.Contents()
+		a.NoError(err)
+	}
+	a.Equal("1.1.0", files["VERSION"])
+	a.Equal("{\n  \"version\": \"1.1.0\"\n}\n", files["package.json"])
+	a.Contains(files["CHANGELOG.md"], "## 1.1.0")
+	a.Contains(files["CHANGELOG.md"], "new flag")
+
+	current, err := r.repo.Head()
+	a.NoError(err)
+	a.Equal(release.Hash, current.Hash())
+	status, err := r.w.Status()
+	a.NoError(err)
+	a.True(status.IsClean(), status.String())
+}
+
+func TestReleaseAll(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{
+		"client/VERSION": "1.0.0", "client/main.js": "1", "server/VERSION": "2.0.0", "server/main.go": "1",
+	})
+	r.tag("client/v1.0.0", first)
+	r.tag("server/v2.0.0", first)
+	r.commit("[feature] client flag", map[string]string{"client/main.js": "2"})
+	r.commit("fix: server bug", map[string]string{"server/main.go": "2"})
+	r.push()
+
+	config := `default_branch: master
+components:
+  - name: client
+    paths: [client]
+  - name: server
+    paths: [server]
+`
+	a.NoError(r.run(config, "release", "--all", "--yes"))
+
+	// each component is released in its own commit, with its own changelog
+	for tag, changelogFile := range map[string]string{"client/v1.1.0": "client/CHANGELOG.md", "server/v2.0.1": "server/CHANGELOG.md"} {
+		ref, err := r.repo.Reference(plumbing.NewTagReferenceName(tag), true)
+		if !a.NoError(err, tag) {
+			continue
+		}
+		release, err := r.repo.CommitObject(ref.Hash())
+		a.NoError(err)
+		paths, err := util.ChangedPaths(release)
+		a.NoError(err)
+		a.Contains(paths, changelogFile)
+	}
+	a.Contains(r.read("client/CHANGELOG.md"), "## 1.1.0")
+	a.Contains(r.read("client/CHANGELOG.md"), "client flag")
+	a.NotContains(r.read("client/CHANGELOG.md"), "server bug")
+	a.Contains(r.read("server/CHANGELOG.md"), "## 2.0.1")
+	a.Contains(r.read("server/CHANGELOG.md"), "server bug")
+	a.NotContains(r.read("server/CHANGELOG.md"), "client flag")
+}
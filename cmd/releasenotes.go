@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(releaseNotesCmd)
+
+	releaseNotesCmd.Flags().Bool("plain", false, "Print plain text instead of markdown")
+	addComponentFlags(releaseNotesCmd, false)
+}
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes [version]",
+	Short: "Print the changelog section of a version",
+	Long: `Print the changelog section of a version, e.g. to publish it as the notes of a GitHub release.
+
+The version defaults to the one in the version file. Only the body of the section is printed, without its
+heading. It fails if the changelog has no section for the version.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plain, err := cmd.Flags().GetBool("plain")
+		if err != nil {
+			return err
+		}
+		name, err := cmd.Flags().GetString("component")
+		if err != nil {
+			return err
+		}
+		component := cfg.Root()
+		if name != "" {
+			component, err = cfg.Component(name)
+			if err != nil {
+				return err
+			}
+		}
+
+		version := ""
+		if len(args) == 1 {
+			version = args[0]
+		} else {
+			version, err = readVersionFile(component.VersionFile)
+			if err != nil {
+				return errors.Wrapf(err, "unable to read the version from %s", component.VersionFile)
+			}
+		}
+
+		notes, err := sectionNotes(component, version)
+		if err != nil {
+			return err
+		}
+		if plain {
+			notes = changelog.PlainText(notes)
+		}
+		fmt.Println(notes)
+		return nil
+	},
+}
+
+// sectionNotes returns the body of the version's section in the component's changelog. The version may have the
+// component's tag prefix.
+func sectionNotes(component config.Component, version string) (string, error) {
+	content, err := ioutil.ReadFile(component.ChangelogFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to read %s", component.ChangelogFile)
+	}
+	version = strings.TrimPrefix(version, component.TagPrefix)
+	section := changelog.Parse(string(content)).Section(strings.TrimPrefix(version, "v"))
+	if section == nil {
+		return "", errors.Errorf("%s has no section for version %s", component.ChangelogFile, version)
+	}
+	return section.Notes(), nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const releaseNotesChangelog = `# Changelog
+
+## 1.1.0 2020-02-01
+
+### Features
+
+* [abc1234](../../commit/abc1234) **Breaking:** new ` + "`flag`" + ` ([#12](../../pull/12))
+
+## 1.0.0 2020-01-01
+
+* initial commit
+`
+
+func TestReleaseNotes(t *testing.T) {
+	config := `default_branch: master
+components:
+  - name: api
+    paths: [api]
+`
+	tests := []struct {
+		name  string
+		args  []string
+		notes string
+		err   string
+	}{
+		{"version file", nil,
+			"### Features\n\n* [abc1234](../../commit/abc1234) **Breaking:** new `flag` ([#12](../../pull/12))\n", ""},
+		{"version", []string{"1.0.0"}, "* initial commit\n", ""},
+		{"tag", []string{"v1.0.0"}, "* initial commit\n", ""},
+		{"plain", []string{"--plain"}, "Features\n\n- abc1234 Breaking: new flag (#12)\n", ""},
+		{"component", []string{"-c", "api"}, "* api fix\n", ""},
+		{"component tag", []string{"-c", "api", "api/v2.0.1"}, "* api fix\n", ""},
+		{"missing section", []string{"0.9.0"}, "", "CHANGELOG.md has no section for version 0.9.0"},
+		{"unknown component", []string{"-c", "web"}, "", "web"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+			r := newTestRepo(t)
+			r.write(map[string]string{
+				"VERSION":          "1.1.0",
+				"CHANGELOG.md":     releaseNotesChangelog,
+				"api/VERSION":      "2.0.1",
+				"api/CHANGELOG.md": "## 2.0.1 2020-03-01\n\n* api fix\n\n## 2.0.0 2020-01-01\n\n* api\n",
+			})
+
+			var err error
+			out := captureStdout(t, func() {
+				err = r.run(config, append([]string{"release-notes"}, test.args...)...)
+			})
+			if test.err != "" {
+				if a.Error(err) {
+					a.Contains(err.Error(), test.err)
+				}
+				return
+			}
+			a.NoError(err)
+			a.Equal(test.notes, out)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	git "gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// testRepo is a git repository on disk with a bare repository as its origin, to run commands in. The current
+// directory is the repository's worktree until the test ends.
+type testRepo struct {
+	t      *testing.T
+	dir    string
+	repo   *git.Repository
+	w      *git.Worktree
+	origin *git.Repository
+	when   time.Time
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	dir, err := ioutil.TempDir("", "bff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	origin, err := git.PlainInit(filepath.Join(dir, "origin.git"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainInit(filepath.Join(dir, "work"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{filepath.Join(dir, "origin.git")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Raw.Section("user").SetOption("name", "Release Bot").SetOption("email", "release@example.com")
+	err = repo.Storer.SetConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// commands read the repo in the current directory, and no user-level config
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(filepath.Join(dir, "work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	previous := cfg
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		os.Setenv("HOME", home)
+		cfg = previous
+	})
+
+	return &testRepo{t: t, dir: dir, repo: repo, w: w, origin: origin, when: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// commit writes files (path -> content) and commits them
+func (r *testRepo) commit(message string, files map[string]string) plumbing.Hash {
+	return r.commitBy("Current User", "user@example.com", message, files)
+}
+
+// commitBy writes files (path -> content) and commits them as the given author
+func (r *testRepo) commitBy(name string, email string, message string, files map[string]string, parents ...plumbing.Hash) plumbing.Hash {
+	r.write(files)
+	for path := range files {
+		_, err := r.w.Add(path)
+		if err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.when = r.when.Add(time.Hour)
+	if len(parents) > 0 {
+		head, err := r.repo.Head()
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		parents = append([]plumbing.Hash{head.Hash()}, parents...)
+	}
+	hash, err := r.w.Commit(message, &git.CommitOptions{
+		Author:  &object.Signature{Name: name, Email: email, When: r.when},
+		Parents: parents,
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return hash
+}
+
+// merge commits a merge of the given commit into the current branch, files are the merged files
+func (r *testRepo) merge(message string, other plumbing.Hash, files map[string]string) plumbing.Hash {
+	return r.commitBy("Current User", "user@example.com", message, files, other)
+}
+
+// write writes files (path -> content) to the worktree, without staging them
+func (r *testRepo) write(files map[string]string) {
+	for path, content := range files {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			r.t.Fatal(err)
+		}
+	}
+}
+
+// checkout switches to the branch, creating it at from unless from is the zero hash
+func (r *testRepo) checkout(branch string, from plumbing.Hash) {
+	err := r.w.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branch),
+		Create: !from.IsZero(),
+		Hash:   from,
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *testRepo) tag(name string, hash plumbing.Hash) {
+	_, err := r.repo.CreateTag(name, hash, nil)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// push pushes master to origin and fetches it back, so that origin/master is the default branch
+func (r *testRepo) push() {
+	err := r.repo.Push(&git.PushOptions{RefSpecs: []gitconfig.RefSpec{"refs/heads/master:refs/heads/master"}})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		r.t.Fatal(err)
+	}
+	err = r.repo.Fetch(&git.FetchOptions{})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		r.t.Fatal(err)
+	}
+}
+
+// read returns the content of a file of the worktree
+func (r *testRepo) read(path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return string(content)
+}
+
+// run runs bff with the given config file content and arguments
+func (r *testRepo) run(config string, args ...string) error {
+	path := filepath.Join(r.dir, "bff.yaml")
+	err := ioutil.WriteFile(path, []byte(config), 0644)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	rootCmd.SetArgs(append([]string{"--config", path}, args...))
+	defer resetFlags(rootCmd)
+	return rootCmd.Execute()
+}
+
+// resetFlags sets the flags of cmd and its subcommands back to their defaults, flags otherwise keep their
+// values from one run to the next
+func resetFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
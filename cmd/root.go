@@ -6,72 +6,65 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
-// var cfgFile string
-var defaultBranchRef string
+var cfgFile string
+
+// cfg is the effective configuration, loaded before any command runs
+var cfg *config.Config
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use: "bff",
+	// Execute prints the error, and usage is not helpful for errors such as failed fetches
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitCode(err))
 	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	// Update HEAD to upstream default branch
-	combinedOut, err := exec.Command("git", "remote", "set-head", "origin", "-a").CombinedOutput()
-	if err != nil {
-		fmt.Println("git remote set-head origin -a output: ", string(combinedOut))
-		fmt.Println("error: ", err)
-		os.Exit(1)
-	}
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("config file (default is ./%s, layered over $HOME/%s)", config.FileName, config.FileName))
+}
 
-	// Get the branch ref, will be used for bump & changelog commands
-	cmdOutput, err := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD").Output()
+// initConfig reads in the config files and BFF_* environment variables.
+func initConfig() {
+	var err error
+	cfg, err = config.Load(cfgFile)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	defaultBranchRef = strings.TrimSpace(string(cmdOutput))
-	// // Here you will define your flags and configuration settings.
-	// // Cobra supports persistent flags, which, if defined here,
-	// // will be global for your application.
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.bff.yaml)")
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	// if cfgFile != "" {
-	// 	// Use config file from the flag.
-	// 	viper.SetConfigFile(cfgFile)
-	// } else {
-	// 	// Find home directory.
-	// 	home, err := homedir.Dir()
-	// 	if err != nil {
-	// 		fmt.Println(err)
-	// 		os.Exit(1)
-	// 	}
-
-	// 	// Search config in home directory with name ".bff" (without extension).
-	// 	viper.AddConfigPath(home)
-	// 	viper.SetConfigName(".bff")
-	// }
+// defaultBranchRef returns the ref of the branch releases are cut from, used by the bump & changelog commands.
+// Unless one is configured, the remote's HEAD is updated and used.
+func defaultBranchRef() (string, error) {
+	if ref := cfg.DefaultBranchRef(); ref != "" {
+		return ref, nil
+	}
 
-	// viper.AutomaticEnv() // read in environment variables that match
+	// Update HEAD to upstream default branch
+	combinedOut, err := exec.Command("git", "remote", "set-head", cfg.Remote, "-a").CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "git remote set-head %s -a output: %s", cfg.Remote, string(combinedOut))
+	}
 
-	// // If a config file is found, read it in.
-	// if err := viper.ReadInConfig(); err == nil {
-	// 	fmt.Println("Using config file:", viper.ConfigFileUsed())
-	// }
+	cmdOutput, err := exec.Command("git", "symbolic-ref", fmt.Sprintf("refs/remotes/%s/HEAD", cfg.Remote)).Output()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to find the default branch")
+	}
+	return strings.TrimSpace(string(cmdOutput)), nil
 }
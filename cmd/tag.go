@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/chanzuckerberg/bff/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// releaseTagger creates the release tags. It is set up before anything is written, so that a missing or
+// locked signing key fails the release before the release commit is made.
+type releaseTagger struct {
+	repo    *git.Repository
+	signKey *openpgp.Entity
+}
+
+func newReleaseTagger(repo *git.Repository) (*releaseTagger, error) {
+	t := &releaseTagger{repo: repo}
+	if !cfg.Tag.Sign {
+		return t, nil
+	}
+	if cfg.Tag.Keyring == "" {
+		return nil, errors.New("tag.keyring must be set to sign tags")
+	}
+	var err error
+	t.signKey, err = util.ReadSigningKey(expandHome(cfg.Tag.Keyring), cfg.Tag.SigningKey, os.Getenv(config.SigningPassphraseEnv))
+	return t, err
+}
+
+// tag tags the commit with hash as the component's version. Depending on the tag settings the tag is
+// lightweight, or annotated with the release notes as its message and optionally signed.
+func (t *releaseTagger) tag(component config.Component, version string, hash plumbing.Hash, notes string) error {
+	name := component.TagName(version)
+	if !cfg.Tag.Annotated && !cfg.Tag.Sign {
+		_, err := t.repo.CreateTag(name, hash, nil)
+		return errors.Wrapf(err, "unable to create tag %s", name)
+	}
+
+	taggerName, taggerEmail, err := util.GetGitAuthor()
+	if err != nil {
+		return errors.Wrap(err, "unable to get the tagger from git config")
+	}
+	title, err := cfg.RenderCommitMessage(component.Name, version)
+	if err != nil {
+		return err
+	}
+	message := title
+	if notes != "" {
+		message = fmt.Sprintf("%s\n\n%s", title, notes)
+	}
+
+	_, err = t.repo.CreateTag(name, hash, &git.CreateTagOptions{
+		Tagger: &object.Signature{
+			Name:  taggerName,
+			Email: taggerEmail,
+			When:  time.Now(),
+		},
+		Message: message,
+		SignKey: t.signKey,
+	})
+	return errors.Wrapf(err, "unable to create tag %s", name)
+}
+
+// releaseNotes returns the entries of the version's section in the component's changelog if there is one,
+// or else lists the subjects of the commits
+func releaseNotes(component config.Component, version string, commits []*object.Commit) (string, error) {
+	content, err := ioutil.ReadFile(component.ChangelogFile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", errors.Wrapf(err, "unable to read %s", component.ChangelogFile)
+	}
+	if section := changelog.Parse(string(content)).Section(version); section != nil && len(section.Entries()) > 0 {
+		return strings.Join(section.Entries(), "\n"), nil
+	}
+
+	lines := []string{}
+	for _, commit := range commits {
+		lines = append(lines, fmt.Sprintf("* %s", strings.Split(commit.Message, "\n")[0]))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// expandHome replaces a leading ~/ with the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/chanzuckerberg/bff/pkg/util"
+	"github.com/chanzuckerberg/bff/pkg/versionfile"
+	"github.com/pkg/errors"
+	prompt "github.com/segmentio/go-prompt"
+	git "gopkg.in/src-d/go-git.v4"
+	gitconfig "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// fetchRemote fetches the configured remote, including all tags
+func fetchRemote(repo *git.Repository) error {
+	options := &git.FetchOptions{
+		RemoteName: cfg.Remote,
+		Tags:       git.AllTags,
+		Progress:   os.Stdout,
+	}
+	err := repo.Fetch(options)
+
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("unable to fetch %w", err)
+	}
+	return nil
+}
+
+// pushRelease pushes the current branch to the default branch of the remote, and the given tags.
+// fetched is the remote branch's commit at the time of the fetch: if the remote branch moved since,
+// nothing is pushed, so that tags are never published for commits that are not on the remote.
+func pushRelease(repo *git.Repository, branchRef string, fetched plumbing.Hash, tags []string) error {
+	branch := strings.TrimPrefix(branchRef, fmt.Sprintf("refs/remotes/%s/", cfg.Remote))
+	remoteBranchRef := plumbing.NewBranchReferenceName(branch)
+
+	remote, err := repo.Remote(cfg.Remote)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find remote %s", cfg.Remote)
+	}
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "unable to list the references of %s", cfg.Remote)
+	}
+	for _, ref := range refs {
+		if ref.Name() == remoteBranchRef && ref.Hash() != fetched {
+			return errors.Errorf("%s/%s moved from %s to %s since the fetch, not pushing the release. "+
+				"The release commit and tags are only local: delete them, pull and release again",
+				cfg.Remote, branch, fetched.String()[:8], ref.Hash().String()[:8])
+		}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return errors.Wrap(err, "could not get HEAD")
+	}
+	if !head.Name().IsBranch() {
+		return errors.New("HEAD is detached, please release from a local branch")
+	}
+	refSpecs := []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("%s:%s", head.Name(), remoteBranchRef))}
+	for _, tag := range tags {
+		tagRef := plumbing.NewTagReferenceName(tag)
+		refSpecs = append(refSpecs, gitconfig.RefSpec(fmt.Sprintf("%s:%s", tagRef, tagRef)))
+	}
+
+	err = repo.Push(&git.PushOptions{
+		RemoteName: cfg.Remote,
+		RefSpecs:   refSpecs,
+		Progress:   os.Stdout,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return errors.Wrapf(err, "unable to push to %s", cfg.Remote)
+	}
+	return nil
+}
+
+// checkClean refuses to release from a working directory with uncommitted changes,
+// unless allowed or confirmed at the prompt
+func checkClean(w *git.Worktree, allowDirty bool, assumeYes bool) error {
+	s, err := w.Status()
+	if err != nil {
+		return fmt.Errorf("Unable to get git status %w", err)
+	}
+
+	if !s.IsClean() && !allowDirty {
+		// HACK(el): go-git does not appear to handle nested .gitignores well
+		// for now, prompt users instead of erroring out immediately
+		ignore := false
+		if isInteractive() && !assumeYes {
+			ignore = prompt.Confirm("your working directory appears to be dirty (uncommited changes), are you sure you want to proceed?")
+		}
+		if !ignore {
+			return errors.New("please release only from a clean working directory (no uncommitted changes), or pass --allow-dirty")
+		}
+	}
+	return nil
+}
+
+// readVersionFile returns the version in the version file at filePath
+func readVersionFile(filePath string) (string, error) {
+	d, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(d)), nil
+}
+
+// writeVersionFile replaces the content of the version file at filePath with version
+func writeVersionFile(filePath string, version string) error {
+	return ioutil.WriteFile(filePath, []byte(version), 0600)
+}
+
+// writeVersionFiles writes version to the component's version file and updates its other version files in place.
+// It returns the paths of all the files to stage in the release commit.
+func writeVersionFiles(component config.Component, version string) ([]string, error) {
+	err := writeVersionFile(component.VersionFile, version)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{component.VersionFile}
+
+	for _, f := range component.VersionFiles {
+		updater, err := versionfile.New(f.Path, f.Format, f.Keys)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read %s", f.Path)
+		}
+		content, err := ioutil.ReadFile(f.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read %s", f.Path)
+		}
+		updated, err := updater.Update(content, version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to update %s", f.Path)
+		}
+		err = ioutil.WriteFile(f.Path, updated, info.Mode())
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to write %s", f.Path)
+		}
+		paths = append(paths, f.Path)
+	}
+	return paths, nil
+}
+
+// commitRelease stages the given paths and commits them with the release commit message for the component's version
+func commitRelease(w *git.Worktree, component string, version string, paths ...string) (plumbing.Hash, error) {
+	for _, path := range paths {
+		_, err := w.Add(path)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+
+	name, email, err := util.GetGitAuthor()
+	if err != nil {
+		fmt.Printf("git author name %s", name)
+		fmt.Printf("git author email %s", email)
+		return plumbing.ZeroHash, err
+	}
+	opts := &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  name,
+			Email: email,
+			When:  time.Now(),
+		},
+	}
+	commitMessage, err := cfg.RenderCommitMessage(component, version)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return w.Commit(commitMessage, opts)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chanzuckerberg/bff/pkg/config"
+	"github.com/stretchr/testify/assert"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestPushRelease(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+	cfg = config.Default()
+
+	fetched := r.commit("initial commit", map[string]string{"VERSION": "1.0.0"})
+	r.push()
+	release := r.commit("release version 1.1.0", map[string]string{"VERSION": "1.1.0"})
+	r.tag("v1.1.0", release)
+
+	a.NoError(pushRelease(r.repo, "refs/remotes/origin/master", fetched, []string{"v1.1.0"}))
+
+	branch, err := r.origin.Reference(plumbing.NewBranchReferenceName("master"), true)
+	a.NoError(err)
+	a.Equal(release, branch.Hash())
+	tag, err := r.origin.Reference(plumbing.NewTagReferenceName("v1.1.0"), true)
+	a.NoError(err)
+	a.Equal(release, tag.Hash())
+}
+
+func TestPushReleaseRemoteMoved(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+	cfg = config.Default()
+
+	fetched := r.commit("initial commit", map[string]string{"VERSION": "1.0.0"})
+	r.push()
+	release := r.commit("release version 1.1.0", map[string]string{"VERSION": "1.1.0"})
+	r.tag("v1.1.0", release)
+
+	// someone else pushes to the branch after the fetch
+	other, err := git.PlainClone(filepath.Join(r.dir, "other"), false, &git.CloneOptions{URL: filepath.Join(r.dir, "origin.git")})
+	a.NoError(err)
+	w, err := other.Worktree()
+	a.NoError(err)
+	moved, err := w.Commit("fix: concurrent change", &git.CommitOptions{
+		Author: &object.Signature{Name: "Someone Else", Email: "else@example.com", When: time.Now()},
+	})
+	a.NoError(err)
+	a.NoError(other.Push(&git.PushOptions{}))
+
+	err = pushRelease(r.repo, "refs/remotes/origin/master", fetched, []string{"v1.1.0"})
+	a.Error(err)
+	a.Contains(err.Error(), "moved")
+
+	branch, err := r.origin.Reference(plumbing.NewBranchReferenceName("master"), true)
+	a.NoError(err)
+	a.Equal(moved, branch.Hash())
+	_, err = r.origin.Reference(plumbing.NewTagReferenceName("v1.1.0"), true)
+	a.Equal(plumbing.ErrReferenceNotFound, err)
+}
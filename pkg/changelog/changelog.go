@@ -0,0 +1,292 @@
+package changelog
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// A release heading looks like "## 0.22.0 2019-06-04" or "## [0.22.0] - 2019-06-04"
+var headingRegexp = regexp.MustCompile(`^## +\[?v?([^\]\s]+)\]?(.*)$`)
+
+// A subsection heading groups the entries of a release, e.g. "### Features"
+var subheadingRegexp = regexp.MustCompile(`^### +(.+?)\s*$`)
+
+// Categories of generated release sections
+const (
+	BreakingChanges = "Breaking Changes"
+	Features        = "Features"
+	FixesOther      = "Fixes/Other"
+)
+
+// Categories are the subsection titles of generated release sections, in the order they are rendered
+var Categories = []string{BreakingChanges, Features, FixesOther}
+
+// Document is a changelog split into its release sections
+type Document struct {
+	// Preamble is everything before the first release section, e.g. the title
+	Preamble []string
+	Sections []*Section
+	// Footer is the block of link reference definitions at the end of the document, e.g. "[1.0.0]: https://...",
+	// as kept by keepachangelog.com changelogs
+	Footer []string
+}
+
+// Section is a single release section of a changelog
+type Section struct {
+	// Version is the version in the heading, without any brackets or v prefix
+	Version string
+	// Heading is the full heading line
+	Heading string
+	// Body is every line after the heading up to the next release section
+	Body []string
+}
+
+// Parse splits the content of a changelog into its release sections
+func Parse(content string) *Document {
+	d := &Document{}
+	if content == "" {
+		return d
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	var current *Section
+	for _, line := range lines {
+		if match := headingRegexp.FindStringSubmatch(line); match != nil {
+			current = &Section{Version: match[1], Heading: line}
+			d.Sections = append(d.Sections, current)
+			continue
+		}
+		if current == nil {
+			d.Preamble = append(d.Preamble, line)
+			continue
+		}
+		current.Body = append(current.Body, line)
+	}
+	if current != nil {
+		current.Body, d.Footer = splitFooter(current.Body)
+	}
+	return d
+}
+
+// splitFooter splits the trailing link reference definitions, and the blank lines between them, off the lines
+func splitFooter(lines []string) ([]string, []string) {
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if linkRegexp.MatchString(lines[i]) {
+			start = i
+		} else if strings.TrimSpace(lines[i]) != "" {
+			break
+		}
+	}
+	return lines[:start], lines[start:]
+}
+
+// String renders the document back to markdown
+func (d *Document) String() string {
+	b := strings.Builder{}
+	for _, line := range d.Preamble {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	for _, s := range d.Sections {
+		b.WriteString(s.Heading)
+		b.WriteByte('\n')
+		for _, line := range s.Body {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	for _, line := range d.Footer {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Section returns the section of the given version, or nil if there is none
+func (d *Document) Section(version string) *Section {
+	for _, s := range d.Sections {
+		if s.Version == version {
+			return s
+		}
+	}
+	return nil
+}
+
+// Upsert replaces the section of the same version as section, or else inserts it above the newest release it is
+// newer than, keeping releases sorted newest first. Sections that are not releases, e.g. Unreleased, stay on top.
+// It returns true if an existing section was replaced.
+func (d *Document) Upsert(section *Section) bool {
+	// sections are separated by a blank line
+	body := section.Body
+	for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+		body = body[:len(body)-1]
+	}
+	section.Body = append(body, "")
+
+	for i, s := range d.Sections {
+		if s.Version == section.Version {
+			d.Sections[i] = section
+			return true
+		}
+	}
+
+	index := len(d.Sections)
+	if version, err := semver.Parse(section.Version); err != nil {
+		index = 0
+	} else {
+		for i, s := range d.Sections {
+			existing, err := semver.Parse(s.Version)
+			if err == nil && version.GT(existing) {
+				index = i
+				break
+			}
+		}
+	}
+
+	if index == 0 && len(d.Preamble) > 0 && strings.TrimSpace(d.Preamble[len(d.Preamble)-1]) != "" {
+		d.Preamble = append(d.Preamble, "")
+	}
+	if index > 0 {
+		previous := d.Sections[index-1]
+		if len(previous.Body) == 0 || strings.TrimSpace(previous.Body[len(previous.Body)-1]) != "" {
+			previous.Body = append(previous.Body, "")
+		}
+	}
+	d.Sections = append(d.Sections, nil)
+	copy(d.Sections[index+1:], d.Sections[index:])
+	d.Sections[index] = section
+	return false
+}
+
+// Entries returns the non-blank lines of the section's body
+func (s *Section) Entries() []string {
+	entries := []string{}
+	for _, line := range s.Body {
+		if strings.TrimSpace(line) != "" {
+			entries = append(entries, line)
+		}
+	}
+	return entries
+}
+
+// Subsection is a group of entries of a release section under a "### " heading
+type Subsection struct {
+	// Title is the text of the heading, it is empty for the entries before the first subsection heading
+	Title   string
+	Entries []string
+}
+
+// Subsections splits the section's entries by their "### " headings
+func (s *Section) Subsections() []*Subsection {
+	subsections := []*Subsection{}
+	current := &Subsection{}
+	for _, line := range s.Entries() {
+		if match := subheadingRegexp.FindStringSubmatch(line); match != nil {
+			if current.Title != "" || len(current.Entries) > 0 {
+				subsections = append(subsections, current)
+			}
+			current = &Subsection{Title: match[1]}
+			continue
+		}
+		current.Entries = append(current.Entries, line)
+	}
+	if current.Title != "" || len(current.Entries) > 0 {
+		subsections = append(subsections, current)
+	}
+	return subsections
+}
+
+// Consolidate replaces every section whose version matches with a single section with the given heading,
+// placed where the first (newest) matching section was. Its body holds the entries of all matching sections,
+// newest first and without duplicates, grouped by subsection. Subsections are ordered like Categories, other
+// subsections follow in the order they first appear. It returns false if no section matched.
+func (d *Document) Consolidate(match func(version string) bool, heading string) bool {
+	merged := &Section{Heading: heading}
+	if m := headingRegexp.FindStringSubmatch(heading); m != nil {
+		merged.Version = m[1]
+	}
+
+	seen := map[string]bool{}
+	subsections := []*Subsection{}
+	byTitle := map[string]*Subsection{}
+	sections := []*Section{}
+	found := false
+	for _, s := range d.Sections {
+		if !match(s.Version) {
+			sections = append(sections, s)
+			continue
+		}
+		if !found {
+			sections = append(sections, merged)
+			found = true
+		}
+		for _, sub := range s.Subsections() {
+			mergedSub, ok := byTitle[sub.Title]
+			if !ok {
+				mergedSub = &Subsection{Title: sub.Title}
+				byTitle[sub.Title] = mergedSub
+				subsections = append(subsections, mergedSub)
+			}
+			for _, entry := range sub.Entries {
+				if !seen[entry] {
+					seen[entry] = true
+					mergedSub.Entries = append(mergedSub.Entries, entry)
+				}
+			}
+		}
+	}
+	if !found {
+		return false
+	}
+
+	sortSubsections(subsections)
+	merged.Body = subsectionsBody(subsections)
+	d.Sections = sections
+	return true
+}
+
+// subsectionsBody renders the subsections as a section body, leaving out empty ones
+func subsectionsBody(subsections []*Subsection) []string {
+	body := []string{""}
+	for _, sub := range subsections {
+		if len(sub.Entries) == 0 {
+			continue
+		}
+		if sub.Title != "" {
+			body = append(body, "### "+sub.Title, "")
+		}
+		body = append(body, sub.Entries...)
+		body = append(body, "")
+	}
+	return body
+}
+
+// sortSubsections orders subsections like Categories, or KeepAChangelogCategories, keeping the order of the others
+func sortSubsections(subsections []*Subsection) {
+	sort.SliceStable(subsections, func(i, j int) bool {
+		return categoryRank(subsections[i].Title) < categoryRank(subsections[j].Title)
+	})
+}
+
+// categoryRank orders entries without a subsection first, then the Categories and KeepAChangelogCategories,
+// then anything else
+func categoryRank(title string) int {
+	if title == "" {
+		return -1
+	}
+	for i, category := range Categories {
+		if category == title {
+			return i
+		}
+	}
+	for i, category := range KeepAChangelogCategories {
+		if strings.EqualFold(category, title) {
+			return len(Categories) + i
+		}
+	}
+	return len(Categories) + len(KeepAChangelogCategories)
+}
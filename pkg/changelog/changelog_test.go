@@ -0,0 +1,206 @@
+package changelog_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+)
+
+const testChangelog = `# Changelog
+
+Some preamble.
+
+## 1.4.0-rc.2 2020-01-03
+
+* [00000002](../../commit/2) Second fix
+* [00000001](../../commit/1) Shared entry
+
+## 1.4.0-rc.1 2020-01-02
+
+* [00000001](../../commit/1) Shared entry
+* [00000000](../../commit/0) First feature
+
+## [1.3.0] - 2020-01-01
+
+* Older entry
+`
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse(testChangelog)
+
+	a.Equal([]string{"# Changelog", "", "Some preamble.", ""}, d.Preamble)
+	a.Len(d.Sections, 3)
+	a.Equal("1.4.0-rc.2", d.Sections[0].Version)
+	a.Equal("1.3.0", d.Sections[2].Version)
+	a.Equal("## [1.3.0] - 2020-01-01", d.Sections[2].Heading)
+	a.Equal([]string{"* Older entry"}, d.Sections[2].Entries())
+
+	a.Equal(testChangelog, d.String())
+}
+
+func TestParseEmpty(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse("")
+	a.Empty(d.Sections)
+	a.Equal("", d.String())
+}
+
+func TestConsolidate(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse(testChangelog)
+
+	ok := d.Consolidate(func(v string) bool { return strings.HasPrefix(v, "1.4.0-") }, "## 1.4.0 2020-01-04")
+	a.True(ok)
+	a.Equal(`# Changelog
+
+Some preamble.
+
+## 1.4.0 2020-01-04
+
+* [00000002](../../commit/2) Second fix
+* [00000001](../../commit/1) Shared entry
+* [00000000](../../commit/0) First feature
+
+## [1.3.0] - 2020-01-01
+
+* Older entry
+`, d.String())
+	a.Equal("1.4.0", d.Sections[0].Version)
+
+	ok = d.Consolidate(func(v string) bool { return v == "2.0.0-rc.1" }, "## 2.0.0 2020-01-04")
+	a.False(ok)
+}
+
+func TestSection(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse(testChangelog)
+
+	s := d.Section("1.3.0")
+	a.NotNil(s)
+	a.Equal("## [1.3.0] - 2020-01-01", s.Heading)
+	a.Nil(d.Section("1.2.0"))
+}
+
+func TestConsolidateSubsections(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse(`# Changelog
+
+## 2.0.0-rc.2 2020-01-03
+
+### Fixes/Other
+
+* Second fix
+
+### Notes
+
+* Custom subsection
+
+## 2.0.0-rc.1 2020-01-02
+
+### Breaking Changes
+
+* Dropped the old API
+
+### Features
+
+* New API
+
+### Fixes/Other
+
+* First fix
+`)
+	a.Equal([]*changelog.Subsection{
+		{Title: changelog.FixesOther, Entries: []string{"* Second fix"}},
+		{Title: "Notes", Entries: []string{"* Custom subsection"}},
+	}, d.Sections[0].Subsections())
+
+	ok := d.Consolidate(func(v string) bool { return strings.HasPrefix(v, "2.0.0-") }, "## 2.0.0 2020-01-04")
+	a.True(ok)
+	a.Equal(`# Changelog
+
+## 2.0.0 2020-01-04
+
+### Breaking Changes
+
+* Dropped the old API
+
+### Features
+
+* New API
+
+### Fixes/Other
+
+* Second fix
+* First fix
+
+### Notes
+
+* Custom subsection
+
+`, d.String())
+}
+
+func TestUpsert(t *testing.T) {
+	var tests = []struct {
+		name     string
+		content  string
+		section  string
+		want     string
+		replaced bool
+	}{
+		{
+			"newest release goes on top",
+			testChangelog,
+			"## 1.4.0 2020-01-04\n\n* New entry\n",
+			"# Changelog\n\nSome preamble.\n\n## 1.4.0 2020-01-04\n\n* New entry\n\n" + testChangelog[strings.Index(testChangelog, "## 1.4.0-rc.2"):],
+			false,
+		},
+		{
+			"same version is replaced",
+			"# Changelog\n\n## 1.1.0 2020-01-02\n\n* Old entry\n\n## 1.0.0 2020-01-01\n\n* First\n",
+			"## 1.1.0 2020-01-03\n\n* Regenerated entry\n",
+			"# Changelog\n\n## 1.1.0 2020-01-03\n\n* Regenerated entry\n\n## 1.0.0 2020-01-01\n\n* First\n",
+			true,
+		},
+		{
+			"older release goes in order",
+			"# Changelog\n\n## Unreleased\n\n* Pending\n\n## 1.2.0 2020-01-03\n\n* Third\n\n## 1.0.0 2020-01-01\n\n* First",
+			"## 1.1.0 2020-01-02\n\n* Second\n",
+			"# Changelog\n\n## Unreleased\n\n* Pending\n\n## 1.2.0 2020-01-03\n\n* Third\n\n## 1.1.0 2020-01-02\n\n* Second\n\n## 1.0.0 2020-01-01\n\n* First\n",
+			false,
+		},
+		{
+			"oldest release goes last",
+			"# Changelog\n\n## 1.0.0 2020-01-01\n\n* First",
+			"## 0.9.0 2019-12-31\n\n* Zeroth\n",
+			"# Changelog\n\n## 1.0.0 2020-01-01\n\n* First\n\n## 0.9.0 2019-12-31\n\n* Zeroth\n\n",
+			false,
+		},
+		{
+			"long preamble without sections",
+			"# Changelog\n\nAll notable changes.\nSee the docs.",
+			"## 1.0.0 2020-01-01\n\n* First\n",
+			"# Changelog\n\nAll notable changes.\nSee the docs.\n\n## 1.0.0 2020-01-01\n\n* First\n\n",
+			false,
+		},
+		{
+			"empty file",
+			"",
+			"## 1.0.0 2020-01-01\n\n* First\n",
+			"## 1.0.0 2020-01-01\n\n* First\n\n",
+			false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+			d := changelog.Parse(test.content)
+			replaced := d.Upsert(changelog.Parse(test.section).Sections[0])
+			a.Equal(test.replaced, replaced)
+			a.Equal(test.want, d.String())
+		})
+	}
+}
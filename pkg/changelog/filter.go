@@ -0,0 +1,87 @@
+package changelog
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Rule matches commits, every field that is set must match
+type Rule struct {
+	// Subject matches the first line of the message
+	Subject *regexp.Regexp
+	// Author matches the author as "Name <email>"
+	Author *regexp.Regexp
+	// Merge matches merge commits if true and other commits if false, any commit if nil
+	Merge *bool
+	// Paths match the commits that only change files matching them. A path matches the file itself,
+	// the files in the directory of that name, or the files matching it as a glob.
+	Paths []string
+}
+
+// Filter decides which commits are listed in the changelog
+type Filter struct {
+	// Include lists only the commits matching any of the rules, if there are any
+	Include []Rule
+	// Exclude leaves out the commits matching any of the rules, even if they are included
+	Exclude []Rule
+}
+
+// Keep reports whether the commit, which changes paths, is listed
+func (f Filter) Keep(c *object.Commit, paths []string) bool {
+	for _, rule := range f.Exclude {
+		if rule.Matches(c, paths) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, rule := range f.Include {
+		if rule.Matches(c, paths) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the commit, which changes paths, matches the rule
+func (r Rule) Matches(c *object.Commit, paths []string) bool {
+	if r.Subject != nil && !r.Subject.MatchString(strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)[0]) {
+		return false
+	}
+	if r.Author != nil && !r.Author.MatchString(fmt.Sprintf("%s <%s>", c.Author.Name, c.Author.Email)) {
+		return false
+	}
+	if r.Merge != nil && *r.Merge != (c.NumParents() > 1) {
+		return false
+	}
+	if len(r.Paths) > 0 {
+		if len(paths) == 0 {
+			return false
+		}
+		for _, p := range paths {
+			if !matchesAnyPath(r.Paths, p) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchesAnyPath(patterns []string, filePath string) bool {
+	filePath = path.Clean(filePath)
+	for _, pattern := range patterns {
+		pattern = path.Clean(pattern)
+		if pattern == "." || filePath == pattern || strings.HasPrefix(filePath, pattern+"/") {
+			return true
+		}
+		if ok, _ := path.Match(pattern, filePath); ok {
+			return true
+		}
+	}
+	return false
+}
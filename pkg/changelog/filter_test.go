@@ -0,0 +1,59 @@
+package changelog_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestFilter(t *testing.T) {
+	yes := true
+	filter := changelog.Filter{
+		Include: []changelog.Rule{
+			{Subject: regexp.MustCompile(`^(feat|fix)`)},
+			{Paths: []string{"api"}},
+		},
+		Exclude: []changelog.Rule{
+			{Author: regexp.MustCompile(`dependabot`)},
+			{Merge: &yes},
+			{Paths: []string{"docs", "*.md"}},
+			{Subject: regexp.MustCompile(`(?i)typo`), Paths: []string{"src"}},
+		},
+	}
+	commit := func(message string, author string, parents int) *object.Commit {
+		return &object.Commit{
+			Message:      message,
+			Author:       object.Signature{Name: author, Email: author + "@example.com"},
+			ParentHashes: make([]plumbing.Hash, parents),
+		}
+	}
+
+	var tests = []struct {
+		name   string
+		commit *object.Commit
+		paths  []string
+		want   bool
+	}{
+		{"included by subject", commit("feat: a feature\n\nBody", "jane", 1), []string{"src/a.go"}, true},
+		{"included by paths", commit("refactor", "jane", 1), []string{"api/a.go", "api/b.go"}, true},
+		{"not included", commit("refactor", "jane", 1), []string{"src/a.go", "api/a.go"}, false},
+		{"excluded by author", commit("fix(deps): bump", "dependabot[bot]", 1), []string{"go.mod"}, false},
+		{"excluded merge", commit("fix: merge branch", "jane", 2), []string{"src/a.go"}, false},
+		{"excluded docs", commit("fix: docs", "jane", 1), []string{"docs/a.txt", "README.md"}, false},
+		{"docs and code", commit("fix: code and docs", "jane", 1), []string{"docs/a.txt", "src/a.go"}, true},
+		{"excluded typo in src", commit("fix: Typo", "jane", 1), []string{"src/a.go"}, false},
+		{"typo elsewhere", commit("fix: typo", "jane", 1), []string{"api/a.go"}, true},
+		{"no paths", commit("fix: empty", "jane", 1), []string{}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, filter.Keep(test.commit, test.paths))
+		})
+	}
+
+	assert.True(t, changelog.Filter{}.Keep(commit("anything", "jane", 2), nil))
+}
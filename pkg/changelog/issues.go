@@ -0,0 +1,102 @@
+package changelog
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// A closing keyword right before a reference, e.g. "Fixes #123" or the "Closes: JIRA-456" trailer
+var closingRegexp = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s*$`)
+
+// IssuePattern finds the references to an issue tracker in commit messages
+type IssuePattern struct {
+	// Regexp matches a reference, e.g. #123 or JIRA-456. Its first group, if any, is the issue ID.
+	Regexp *regexp.Regexp
+	// URL renders the link to an issue from its .ID and .Ref
+	URL *template.Template
+}
+
+// Issue is a reference to an issue in a commit message
+type Issue struct {
+	// Ref is the reference as written, e.g. #123 or JIRA-456, and ID the issue ID in it, e.g. 123
+	Ref string `json:"ref" yaml:"ref"`
+	ID  string `json:"id" yaml:"id"`
+	URL string `json:"url" yaml:"url"`
+	// Closed is true if the commit closes the issue, e.g. "Fixes #123"
+	Closed bool `json:"closed" yaml:"closed"`
+}
+
+// NewIssuePattern returns the issue pattern matching references with the regular expression pattern and linking
+// them with the text/template url, e.g. "https://github.com/org/repo/issues/{{.ID}}"
+func NewIssuePattern(pattern string, url string) (IssuePattern, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return IssuePattern{}, errors.Wrapf(err, "invalid issue pattern %s", pattern)
+	}
+	t, err := template.New("issue").Option("missingkey=error").Parse(url)
+	if err != nil {
+		return IssuePattern{}, errors.Wrapf(err, "invalid issue URL %s", url)
+	}
+	p := IssuePattern{Regexp: re, URL: t}
+	_, err = p.link("1", "#1")
+	return p, err
+}
+
+func (p IssuePattern) link(id string, ref string) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	err := p.URL.Execute(buf, struct{ ID, Ref string }{id, ref})
+	return buf.String(), errors.Wrap(err, "unable to render issue URL")
+}
+
+// FindIssues returns the issues referenced in text, in the order they first appear
+func FindIssues(text string, patterns []IssuePattern) []Issue {
+	issues := []Issue{}
+	byRef := map[string]int{}
+	for _, p := range patterns {
+		for _, match := range p.Regexp.FindAllStringSubmatchIndex(text, -1) {
+			ref := text[match[0]:match[1]]
+			id := ref
+			if len(match) > 2 && match[2] >= 0 {
+				id = text[match[2]:match[3]]
+			}
+			lineStart := strings.LastIndex(text[:match[0]], "\n") + 1
+			closed := closingRegexp.MatchString(text[lineStart:match[0]])
+
+			if i, ok := byRef[ref]; ok {
+				issues[i].Closed = issues[i].Closed || closed
+				continue
+			}
+			url, err := p.link(id, ref)
+			if err != nil {
+				continue
+			}
+			byRef[ref] = len(issues)
+			issues = append(issues, Issue{Ref: ref, ID: id, URL: url, Closed: closed})
+		}
+	}
+	return issues
+}
+
+// LinkIssues sets the issues referenced in the subject and body of the commit, trailers included
+func (c *Commit) LinkIssues(patterns []IssuePattern) {
+	c.Issues = FindIssues(c.Subject+"\n"+c.Body, patterns)
+}
+
+// ClosedIssues returns the issues closed by the commits, without duplicates
+func ClosedIssues(commits []Commit) []Issue {
+	closed := []Issue{}
+	seen := map[string]bool{}
+	for _, c := range commits {
+		for _, issue := range c.Issues {
+			if issue.Closed && !seen[issue.Ref] {
+				seen[issue.Ref] = true
+				closed = append(closed, issue)
+			}
+		}
+	}
+	return closed
+}
@@ -0,0 +1,78 @@
+package changelog_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/chanzuckerberg/bff/pkg/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIssuePatterns(t *testing.T) []changelog.IssuePattern {
+	patterns := []changelog.IssuePattern{}
+	for _, p := range [][2]string{
+		{`#(\d+)`, "https://github.com/org/repo/issues/{{.ID}}"},
+		{`\bJIRA-\d+\b`, "https://example.atlassian.net/browse/{{.Ref}}"},
+		{`\bLIN-\d+\b`, "https://linear.app/org/issue/{{.Ref}}"},
+	} {
+		pattern, err := changelog.NewIssuePattern(p[0], p[1])
+		assert.NoError(t, err)
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+func TestFindIssues(t *testing.T) {
+	a := assert.New(t)
+	patterns := testIssuePatterns(t)
+
+	issues := changelog.FindIssues(`fix: crash on start, see #12 and JIRA-456
+
+Fixes #123, relates to LIN-78.
+Also mentions #12 again.
+
+Closes: JIRA-456
+Refs: LIN-9`, patterns)
+	a.Equal([]changelog.Issue{
+		{Ref: "#12", ID: "12", URL: "https://github.com/org/repo/issues/12"},
+		{Ref: "#123", ID: "123", URL: "https://github.com/org/repo/issues/123", Closed: true},
+		{Ref: "JIRA-456", ID: "JIRA-456", URL: "https://example.atlassian.net/browse/JIRA-456", Closed: true},
+		{Ref: "LIN-78", ID: "LIN-78", URL: "https://linear.app/org/issue/LIN-78"},
+		{Ref: "LIN-9", ID: "LIN-9", URL: "https://linear.app/org/issue/LIN-9"},
+	}, issues)
+
+	a.Empty(changelog.FindIssues("no references", patterns))
+
+	_, err := changelog.NewIssuePattern(`(`, "https://example.com")
+	a.Error(err)
+	_, err = changelog.NewIssuePattern(`#\d+`, "https://example.com/{{.Nope}}")
+	a.Error(err)
+}
+
+func TestRenderIssues(t *testing.T) {
+	a := assert.New(t)
+	r := testRelease(forge.Forge{})
+	r.Commits = r.Commits[:2]
+	r.Commits[0].Body = "Fixes #7, see JIRA-1"
+	for i := range r.Commits {
+		r.Commits[i].LinkIssues(testIssuePatterns(t))
+	}
+	r.ClosedIssues = changelog.ClosedIssues(r.Commits)
+
+	out, err := changelog.Render(changelog.DefaultTemplate, r)
+	a.NoError(err)
+	a.Equal(`## 1.0.0 2020-01-02
+
+### Breaking Changes
+
+* [22222222](../../commit/2222222222222222222222222222222222222222) feat!: drop the old API
+
+### Fixes/Other
+
+* [33333333](../../commit/3333333333333333333333333333333333333333) fix: a bug ([#12](../../pull/12)) ([#7](https://github.com/org/repo/issues/7), [JIRA-1](https://example.atlassian.net/browse/JIRA-1))
+
+### Closed Issues
+
+* [#7](https://github.com/org/repo/issues/7)
+`, out)
+}
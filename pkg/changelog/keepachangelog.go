@@ -0,0 +1,170 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// KeepAChangelog is the changelog format of https://keepachangelog.com: a hand-maintained Unreleased section,
+// "## [1.0.0] - 2019-06-04" headings and link reference definitions at the bottom
+const KeepAChangelog = "keepachangelog"
+
+// Unreleased is the version of the section collecting the changes of the next release
+const Unreleased = "Unreleased"
+
+// Keep a Changelog subsections
+const (
+	Added      = "Added"
+	Changed    = "Changed"
+	Deprecated = "Deprecated"
+	Removed    = "Removed"
+	Fixed      = "Fixed"
+	Security   = "Security"
+)
+
+// KeepAChangelogCategories are the Keep a Changelog subsection titles, in the order they are rendered
+var KeepAChangelogCategories = []string{Added, Changed, Deprecated, Removed, Fixed, Security}
+
+// KeepAChangelogTemplate renders a release section like "## [0.22.0] - 2019-06-04" followed by the commits
+// grouped by Keep a Changelog subsection
+const KeepAChangelogTemplate = `## [{{.Version}}] - {{.Date.Format "2006-01-02"}}
+{{range .KeepAChangelogCategories}}
+### {{.Title}}
+
+{{range .Commits}}- {{if .Breaking}}**Breaking:** {{end}}{{.Subject}} ([{{.ShortHash}}]({{.URL}})){{if .PR}} ([{{.PRRef}}]({{.PRURL}})){{end}}{{template "issues" .}}{{if $.Attribution}} by {{.Mentions}}{{end}}
+{{end}}{{end}}{{if .ClosedIssues}}
+### Closed Issues
+
+{{range .ClosedIssues}}- [{{.Ref}}]({{.URL}})
+{{end}}{{end}}{{if .NewContributors}}
+### New Contributors
+
+{{range .NewContributors}}- {{.Mention}}
+{{end}}{{end}}`
+
+// A link reference definition, e.g. "[1.0.0]: https://github.com/org/repo/compare/v0.9.0...v1.0.0"
+var linkRegexp = regexp.MustCompile(`^\[([^\]]+)\]:\s*(\S*)`)
+
+// ReleaseUnreleased turns the Unreleased section into section: the entries of each Unreleased subsection go
+// before the entries of the same subsection of section, and of an existing section of the same version, without
+// duplicates. The Unreleased section is then emptied, or added on top if there is none. It returns true if an
+// existing section of the same version was replaced.
+func (d *Document) ReleaseUnreleased(section *Section) bool {
+	unreleased := d.unreleased()
+	if unreleased == nil {
+		unreleased = &Section{Version: Unreleased, Heading: "## [" + Unreleased + "]"}
+		d.Upsert(unreleased)
+	}
+
+	sources := []*Section{unreleased}
+	if existing := d.Section(section.Version); existing != nil {
+		sources = append(sources, existing)
+	}
+	sources = append(sources, section)
+
+	seen := map[string]bool{}
+	subsections := []*Subsection{}
+	byTitle := map[string]*Subsection{}
+	for _, s := range sources {
+		for _, sub := range s.Subsections() {
+			key := strings.ToLower(sub.Title)
+			merged, ok := byTitle[key]
+			if !ok {
+				merged = &Subsection{Title: sub.Title}
+				byTitle[key] = merged
+				subsections = append(subsections, merged)
+			}
+			for _, entry := range sub.Entries {
+				if !seen[entry] {
+					seen[entry] = true
+					merged.Entries = append(merged.Entries, entry)
+				}
+			}
+		}
+	}
+	sortSubsections(subsections)
+
+	section.Body = subsectionsBody(subsections)
+	unreleased.Body = []string{""}
+	return d.Upsert(section)
+}
+
+// unreleased returns the Unreleased section, whatever its case, or nil if there is none
+func (d *Document) unreleased() *Section {
+	for _, s := range d.Sections {
+		if strings.EqualFold(s.Version, Unreleased) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Link returns the URL of the link reference definition with the label, compared case-insensitively
+func (d *Document) Link(label string) (string, bool) {
+	for _, line := range d.Footer {
+		if match := linkRegexp.FindStringSubmatch(line); match != nil && strings.EqualFold(match[1], label) {
+			return match[2], true
+		}
+	}
+	return "", false
+}
+
+// SetLink sets the URL of the link reference definition with the label, keeping the case of an existing label.
+// A new definition for a version goes above the definitions of older versions, any other goes first.
+func (d *Document) SetLink(label string, url string) {
+	for i, line := range d.Footer {
+		if match := linkRegexp.FindStringSubmatch(line); match != nil && strings.EqualFold(match[1], label) {
+			d.Footer[i] = "[" + match[1] + "]: " + url
+			return
+		}
+	}
+
+	definition := "[" + label + "]: " + url
+	if len(d.Footer) == 0 {
+		if len(d.Sections) > 0 {
+			last := d.Sections[len(d.Sections)-1]
+			if len(last.Body) == 0 || strings.TrimSpace(last.Body[len(last.Body)-1]) != "" {
+				d.Footer = append(d.Footer, "")
+			}
+		}
+		d.Footer = append(d.Footer, definition)
+		return
+	}
+
+	index := -1
+	version, err := semver.ParseTolerant(label)
+	for i, line := range d.Footer {
+		match := linkRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if err != nil {
+			index = i
+			break
+		}
+		existing, parseErr := semver.ParseTolerant(match[1])
+		if parseErr == nil && version.GT(existing) {
+			index = i
+			break
+		}
+		index = i + 1
+	}
+	if index < 0 {
+		index = len(d.Footer)
+	}
+	d.Footer = append(d.Footer, "")
+	copy(d.Footer[index+1:], d.Footer[index:])
+	d.Footer[index] = definition
+}
+
+// RemoveLink removes the link reference definition with the label, compared case-insensitively
+func (d *Document) RemoveLink(label string) {
+	for i, line := range d.Footer {
+		if match := linkRegexp.FindStringSubmatch(line); match != nil && strings.EqualFold(match[1], label) {
+			d.Footer = append(d.Footer[:i], d.Footer[i+1:]...)
+			return
+		}
+	}
+}
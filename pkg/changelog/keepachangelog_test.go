@@ -0,0 +1,159 @@
+package changelog_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/chanzuckerberg/bff/pkg/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKeepAChangelog = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+### Added
+
+- Hand-written feature
+
+### Security
+
+- Patched a hole
+
+## [0.9.0] - 2020-01-01
+
+### Added
+
+- First release
+
+[Unreleased]: https://github.com/org/repo/compare/v0.9.0...HEAD
+[0.9.0]: https://github.com/org/repo/releases/tag/v0.9.0
+`
+
+func TestParseFooter(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse(testKeepAChangelog)
+
+	a.Len(d.Sections, 2)
+	a.Equal([]string{"- First release"}, d.Sections[1].Subsections()[0].Entries)
+	a.Len(d.Footer, 2)
+	url, ok := d.Link("unreleased")
+	a.True(ok)
+	a.Equal("https://github.com/org/repo/compare/v0.9.0...HEAD", url)
+	_, ok = d.Link("1.0.0")
+	a.False(ok)
+
+	a.Equal(testKeepAChangelog, d.String())
+}
+
+func TestRenderKeepAChangelogTemplate(t *testing.T) {
+	a := assert.New(t)
+
+	out, err := changelog.Render(changelog.KeepAChangelogTemplate, testRelease(forge.Forge{}))
+	a.NoError(err)
+	a.Equal(`## [1.0.0] - 2020-01-02
+
+### Added
+
+- [feature] new API ([11111111](../../commit/1111111111111111111111111111111111111111))
+
+### Changed
+
+- **Breaking:** feat!: drop the old API ([22222222](../../commit/2222222222222222222222222222222222222222))
+- docs: readme ([00000000](../../commit/0000000000000000000000000000000000000000))
+
+### Fixed
+
+- fix: a bug ([33333333](../../commit/3333333333333333333333333333333333333333)) ([#12](../../pull/12))
+`, out)
+}
+
+func TestReleaseUnreleased(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse(testKeepAChangelog)
+
+	generated := changelog.Parse("## [1.0.0] - 2020-01-02\n\n### Fixed\n\n- A bug\n\n### Added\n\n- Generated feature\n").Sections[0]
+	a.False(d.ReleaseUnreleased(generated))
+	d.SetLink(changelog.Unreleased, "https://github.com/org/repo/compare/v1.0.0...HEAD")
+	d.SetLink("1.0.0", "https://github.com/org/repo/compare/v0.9.0...v1.0.0")
+
+	a.Equal(`# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+## [1.0.0] - 2020-01-02
+
+### Added
+
+- Hand-written feature
+- Generated feature
+
+### Fixed
+
+- A bug
+
+### Security
+
+- Patched a hole
+
+## [0.9.0] - 2020-01-01
+
+### Added
+
+- First release
+
+[Unreleased]: https://github.com/org/repo/compare/v1.0.0...HEAD
+[1.0.0]: https://github.com/org/repo/compare/v0.9.0...v1.0.0
+[0.9.0]: https://github.com/org/repo/releases/tag/v0.9.0
+`, d.String())
+
+	// running it again keeps the released hand-written entries
+	regenerated := changelog.Parse("## [1.0.0] - 2020-01-03\n\n### Fixed\n\n- A bug\n").Sections[0]
+	a.True(d.ReleaseUnreleased(regenerated))
+	a.Equal([]string{"### Added", "- Hand-written feature", "- Generated feature", "### Fixed", "- A bug", "### Security", "- Patched a hole"},
+		d.Section("1.0.0").Entries())
+	a.Equal("## [1.0.0] - 2020-01-03", d.Section("1.0.0").Heading)
+}
+
+func TestReleaseUnreleasedWithoutUnreleased(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse("# Changelog\n\n## [0.9.0] - 2020-01-01\n\n- First release\n")
+
+	d.ReleaseUnreleased(changelog.Parse("## [1.0.0] - 2020-01-02\n\n### Fixed\n\n- A bug\n").Sections[0])
+	d.SetLink("1.0.0", "https://example.com/1.0.0")
+	d.SetLink("0.9.0", "https://example.com/0.9.0")
+	d.SetLink(changelog.Unreleased, "https://example.com/unreleased")
+	a.Equal(`# Changelog
+
+## [Unreleased]
+
+## [1.0.0] - 2020-01-02
+
+### Fixed
+
+- A bug
+
+## [0.9.0] - 2020-01-01
+
+- First release
+
+[Unreleased]: https://example.com/unreleased
+[1.0.0]: https://example.com/1.0.0
+[0.9.0]: https://example.com/0.9.0
+`, d.String())
+}
+
+func TestRemoveLink(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse(testKeepAChangelog)
+
+	d.RemoveLink("0.9.0")
+	d.RemoveLink("1.0.0")
+	a.Equal([]string{"[Unreleased]: https://github.com/org/repo/compare/v0.9.0...HEAD"}, d.Footer)
+	_, ok := d.Link("0.9.0")
+	a.False(ok)
+}
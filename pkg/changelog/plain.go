@@ -0,0 +1,46 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// an inline link or image, e.g. [#12](../../pull/12)
+	inlineLinkRegexp = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	// bold or italic text, e.g. **Breaking:**
+	emphasisRegexp = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	// inline code, e.g. `bff bump`
+	codeRegexp = regexp.MustCompile("`([^`]*)`")
+	// a list item, e.g. "* entry" or "- entry"
+	bulletRegexp = regexp.MustCompile(`^(\s*)[*+-]\s+`)
+)
+
+// Notes returns the body of the section without its leading and trailing blank lines
+func (s *Section) Notes() string {
+	start, end := 0, len(s.Body)
+	for start < end && strings.TrimSpace(s.Body[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(s.Body[end-1]) == "" {
+		end--
+	}
+	return strings.Join(s.Body[start:end], "\n")
+}
+
+// PlainText converts the markdown of a changelog section to plain text: links are replaced by their text,
+// emphasis and code marks are dropped, subsection headings lose their hashes and list items start with "- "
+func PlainText(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		if match := subheadingRegexp.FindStringSubmatch(line); match != nil {
+			line = match[1]
+		}
+		line = inlineLinkRegexp.ReplaceAllString(line, "$1")
+		line = emphasisRegexp.ReplaceAllString(line, "$2")
+		line = codeRegexp.ReplaceAllString(line, "$1")
+		line = bulletRegexp.ReplaceAllString(line, "$1- ")
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,28 @@
+package changelog_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotes(t *testing.T) {
+	a := assert.New(t)
+	d := changelog.Parse("## 1.0.0 2020-01-02\n\n### Features\n\n* A feature\n\n\n## 0.9.0 2020-01-01\n")
+	a.Equal("### Features\n\n* A feature", d.Section("1.0.0").Notes())
+	a.Equal("", d.Section("0.9.0").Notes())
+}
+
+func TestPlainText(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(`Breaking Changes
+
+- 22222222 feat!: drop the old API by @octocat
+- Breaking: renamed bff bump ([#12], JIRA-1)
+  - nested item`, changelog.PlainText(`### Breaking Changes
+
+* [22222222](../../commit/2222222222222222222222222222222222222222) feat!: drop the old API by @octocat
+- **Breaking:** renamed `+"`bff bump`"+` ([#12], [JIRA-1](https://example.com/JIRA-1))
+  + nested item`))
+}
@@ -0,0 +1,269 @@
+package changelog
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/chanzuckerberg/bff/pkg/commits"
+	"github.com/chanzuckerberg/bff/pkg/forge"
+	"github.com/chanzuckerberg/bff/pkg/mailmap"
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// DefaultTemplate renders a release section like "## 0.22.0 2019-06-04" followed by the commits
+// grouped by category
+const DefaultTemplate = `## {{if .CompareURL}}[{{.Version}}]({{.CompareURL}}){{else}}{{.Version}}{{end}} {{.Date.Format "2006-01-02"}}
+{{range .Categories}}
+### {{.Title}}
+
+{{range .Commits}}* [{{.ShortHash}}]({{.URL}}) {{.Subject}}{{if .PR}} ([{{.PRRef}}]({{.PRURL}})){{end}}{{template "issues" .}}{{if $.Attribution}} by {{.Mentions}}{{end}}
+{{end}}{{end}}{{if .ClosedIssues}}
+### Closed Issues
+
+{{range .ClosedIssues}}* [{{.Ref}}]({{.URL}})
+{{end}}{{end}}{{if .NewContributors}}
+### New Contributors
+
+{{range .NewContributors}}* {{.Mention}}
+{{end}}{{end}}`
+
+// issuesTemplate renders the issues a commit references, e.g. " ([#123](url), [JIRA-456](url))". Templates use it
+// with {{template "issues" .}} in the range of the commits.
+const issuesTemplate = `{{define "issues"}}{{if .Issues}} ({{range $i, $issue := .Issues}}{{if $i}}, {{end}}[{{$issue.Ref}}]({{$issue.URL}}){{end}}){{end}}{{end}}`
+
+// A pull request number at the end of a subject, as added by squash merges, e.g. "A commit message (#100)"
+var prRegexp = regexp.MustCompile(`\s*\(#(\d+)\)$`)
+
+// Release is what a changelog template receives, and what is printed with --format json or yaml
+type Release struct {
+	// Component is the name of the component released, it is empty for the whole repo
+	Component string `json:"component,omitempty" yaml:"component,omitempty"`
+	Version   string `json:"version" yaml:"version"`
+	// PreviousVersion is the version of the latest release before this one and PreviousTag its tag, they are
+	// empty for the first release
+	PreviousVersion string    `json:"previous_version,omitempty" yaml:"previous_version,omitempty"`
+	PreviousTag     string    `json:"previous_tag,omitempty" yaml:"previous_tag,omitempty"`
+	Date            time.Time `json:"date" yaml:"date"`
+	// CompareURL links to the changes since the previous version, it is empty if there is no previous
+	// version or the forge is unknown
+	CompareURL string `json:"compare_url,omitempty" yaml:"compare_url,omitempty"`
+	// Commits are newest first
+	Commits []Commit `json:"commits" yaml:"commits"`
+	// ClosedIssues are the issues closed by the commits
+	ClosedIssues []Issue `json:"closed_issues,omitempty" yaml:"closed_issues,omitempty"`
+	// NewContributors made their first commit in this release, they are only known with Attribution
+	NewContributors []Contributor `json:"new_contributors,omitempty" yaml:"new_contributors,omitempty"`
+	// Attribution credits the contributors of each commit in the default templates
+	Attribution bool `json:"-" yaml:"-"`
+}
+
+// Commit is a commit of a release
+type Commit struct {
+	Hash      string `json:"hash" yaml:"hash"`
+	ShortHash string `json:"-" yaml:"-"`
+	// Subject is the first line of the message, without the pull request number
+	Subject string `json:"subject" yaml:"subject"`
+	// Body is the rest of the message
+	Body        string `json:"body,omitempty" yaml:"body,omitempty"`
+	Author      string `json:"author" yaml:"author"`
+	AuthorEmail string `json:"author_email" yaml:"author_email"`
+	// CoAuthors are the contributors credited with Co-authored-by trailers
+	CoAuthors []Contributor `json:"co_authors,omitempty" yaml:"co_authors,omitempty"`
+	// PR is the number of the pull request the commit was merged with, or 0
+	PR    int           `json:"pr,omitempty" yaml:"pr,omitempty"`
+	Class commits.Class `json:"class" yaml:"class"`
+	// Issues are the issues referenced in the message
+	Issues []Issue `json:"issues,omitempty" yaml:"issues,omitempty"`
+
+	// URL links to the commit
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// PRURL links to the pull request, or merge request, and PRRef is how the forge refers to it, e.g. #12 or !12
+	PRURL string `json:"pr_url,omitempty" yaml:"pr_url,omitempty"`
+	PRRef string `json:"-" yaml:"-"`
+}
+
+// Category is a group of commits of a release with the same class
+type Category struct {
+	Title   string
+	Commits []Commit
+}
+
+// Contributor is an author or co-author of commits
+type Contributor struct {
+	Name  string `json:"name" yaml:"name"`
+	Email string `json:"email" yaml:"email"`
+	// Handle is the forge username, known from GitHub noreply emails, e.g. octocat for
+	// 583231+octocat@users.noreply.github.com
+	Handle string `json:"handle,omitempty" yaml:"handle,omitempty"`
+}
+
+// A GitHub noreply email, e.g. 583231+octocat@users.noreply.github.com or octocat@users.noreply.github.com
+var noreplyRegexp = regexp.MustCompile(`^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+
+// An identity in a trailer, e.g. "Jane Doe <jane@example.com>"
+var identityRegexp = regexp.MustCompile(`^(.*?)\s*<([^>]*)>$`)
+
+// NewContributor returns the contributor with the canonical identity of name and email in the mailmap, which
+// may be nil
+func NewContributor(name string, email string, m *mailmap.Mailmap) Contributor {
+	name, email = m.Resolve(name, email)
+	c := Contributor{Name: name, Email: email}
+	if match := noreplyRegexp.FindStringSubmatch(strings.ToLower(email)); match != nil {
+		c.Handle = match[1]
+	}
+	return c
+}
+
+// Mention returns how the contributor is credited, @handle if the handle is known or else the name
+func (c Contributor) Mention() string {
+	if c.Handle != "" {
+		return "@" + c.Handle
+	}
+	return c.Name
+}
+
+// Key identifies the contributor, by email if there is one
+func (c Contributor) Key() string {
+	if c.Email != "" {
+		return strings.ToLower(c.Email)
+	}
+	return c.Name
+}
+
+// NewCommit returns the release commit of a git commit, classified with classifier, linked to the forge and with
+// its author and co-authors resolved in the mailmap, which may be nil
+func NewCommit(c *object.Commit, classifier commits.Classifier, f forge.Forge, m *mailmap.Mailmap) Commit {
+	hash := c.Hash.String()
+	lines := strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)
+	author := NewContributor(c.Author.Name, c.Author.Email, m)
+	commit := Commit{
+		Hash:        hash,
+		ShortHash:   hash[:8],
+		Subject:     lines[0],
+		Author:      author.Name,
+		AuthorEmail: author.Email,
+		Class:       classifier.Classify(c.Message),
+		URL:         f.CommitURL(hash),
+	}
+	if len(lines) > 1 {
+		commit.Body = strings.TrimSpace(lines[1])
+	}
+	if match := prRegexp.FindStringSubmatchIndex(commit.Subject); match != nil {
+		commit.PR, _ = strconv.Atoi(commit.Subject[match[2]:match[3]])
+		commit.Subject = commit.Subject[:match[0]]
+		commit.PRURL = f.PullRequestURL(commit.PR)
+		commit.PRRef = f.PullRequestRef(commit.PR)
+	}
+	for _, footer := range commits.Parse(c.Message).Footers {
+		if !strings.EqualFold(footer.Token, "Co-authored-by") {
+			continue
+		}
+		if match := identityRegexp.FindStringSubmatch(footer.Value); match != nil {
+			commit.CoAuthors = append(commit.CoAuthors, NewContributor(match[1], match[2], m))
+		}
+	}
+	return commit
+}
+
+// Contributors returns the author and the co-authors of the commit, without duplicates
+func (c Commit) Contributors() []Contributor {
+	contributors := []Contributor{}
+	seen := map[string]bool{}
+	for _, contributor := range append([]Contributor{NewContributor(c.Author, c.AuthorEmail, nil)}, c.CoAuthors...) {
+		if !seen[contributor.Key()] {
+			seen[contributor.Key()] = true
+			contributors = append(contributors, contributor)
+		}
+	}
+	return contributors
+}
+
+// Mentions credits the contributors of the commit, e.g. "@octocat, Jane Doe and @hubot"
+func (c Commit) Mentions() string {
+	mentions := []string{}
+	for _, contributor := range c.Contributors() {
+		mentions = append(mentions, contributor.Mention())
+	}
+	if len(mentions) < 2 {
+		return strings.Join(mentions, "")
+	}
+	return strings.Join(mentions[:len(mentions)-1], ", ") + " and " + mentions[len(mentions)-1]
+}
+
+// Breaking reports whether the commit is a breaking change
+func (c Commit) Breaking() bool {
+	return c.Class == commits.Breaking
+}
+
+// Feature reports whether the commit is a feature
+func (c Commit) Feature() bool {
+	return c.Class == commits.Feature
+}
+
+// Categories groups the commits under the Categories titles, leaving out empty ones.
+// Fixes and other commits share a category.
+func (r Release) Categories() []Category {
+	return r.group(Categories, func(c Commit) string {
+		switch c.Class {
+		case commits.Breaking:
+			return BreakingChanges
+		case commits.Feature:
+			return Features
+		default:
+			return FixesOther
+		}
+	})
+}
+
+// KeepAChangelogCategories groups the commits under the KeepAChangelogCategories titles, leaving out empty ones.
+// Features are Added and fixes Fixed, breaking changes and other commits are Changed.
+func (r Release) KeepAChangelogCategories() []Category {
+	return r.group(KeepAChangelogCategories, func(c Commit) string {
+		switch c.Class {
+		case commits.Feature:
+			return Added
+		case commits.Fix:
+			return Fixed
+		default:
+			return Changed
+		}
+	})
+}
+
+// group groups the commits by the title returned for each, in the order of titles
+func (r Release) group(titles []string, title func(Commit) string) []Category {
+	byTitle := map[string][]Commit{}
+	for _, c := range r.Commits {
+		byTitle[title(c)] = append(byTitle[title(c)], c)
+	}
+
+	categories := []Category{}
+	for _, t := range titles {
+		if len(byTitle[t]) > 0 {
+			categories = append(categories, Category{Title: t, Commits: byTitle[t]})
+		}
+	}
+	return categories
+}
+
+// Render renders the release with the text/template in text
+func Render(text string, r Release) (string, error) {
+	t, err := template.New("changelog").Parse(issuesTemplate)
+	if err == nil {
+		_, err = t.Parse(text)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "unable to parse changelog template")
+	}
+	buf := bytes.NewBuffer(nil)
+	err = t.Execute(buf, r)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to render changelog template")
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,243 @@
+package changelog_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chanzuckerberg/bff/pkg/changelog"
+	"github.com/chanzuckerberg/bff/pkg/commits"
+	"github.com/chanzuckerberg/bff/pkg/forge"
+	"github.com/chanzuckerberg/bff/pkg/mailmap"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func testRelease(f forge.Forge) changelog.Release {
+	classifier := commits.DefaultClassifier()
+	commit := func(hash, message string) changelog.Commit {
+		return changelog.NewCommit(&object.Commit{
+			Hash:    plumbing.NewHash(hash),
+			Message: message,
+			Author:  object.Signature{Name: "Jane Doe", Email: "jane@example.com"},
+		}, classifier, f, nil)
+	}
+	return changelog.Release{
+		Version:         "1.0.0",
+		PreviousVersion: "0.9.0",
+		Date:            time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Commits: []changelog.Commit{
+			commit("3333333333333333333333333333333333333333", "fix: a bug (#12)"),
+			commit("2222222222222222222222222222222222222222", "feat!: drop the old API"),
+			commit("1111111111111111111111111111111111111111", "[feature] new API\n\nWith a body.\n"),
+			commit("0000000000000000000000000000000000000000", "docs: readme"),
+		},
+	}
+}
+
+func TestNewCommit(t *testing.T) {
+	a := assert.New(t)
+	r := testRelease(forge.Forge{})
+
+	a.Equal(changelog.Commit{
+		Hash:        "3333333333333333333333333333333333333333",
+		ShortHash:   "33333333",
+		Subject:     "fix: a bug",
+		Author:      "Jane Doe",
+		AuthorEmail: "jane@example.com",
+		PR:          12,
+		Class:       commits.Fix,
+		URL:         "../../commit/3333333333333333333333333333333333333333",
+		PRURL:       "../../pull/12",
+		PRRef:       "#12",
+	}, r.Commits[0])
+	a.True(r.Commits[1].Breaking())
+	a.True(r.Commits[2].Feature())
+	a.Equal("With a body.", r.Commits[2].Body)
+}
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	a := assert.New(t)
+
+	out, err := changelog.Render(changelog.DefaultTemplate, testRelease(forge.Forge{}))
+	a.NoError(err)
+	a.Equal(`## 1.0.0 2020-01-02
+
+### Breaking Changes
+
+* [22222222](../../commit/2222222222222222222222222222222222222222) feat!: drop the old API
+
+### Features
+
+* [11111111](../../commit/1111111111111111111111111111111111111111) [feature] new API
+
+### Fixes/Other
+
+* [33333333](../../commit/3333333333333333333333333333333333333333) fix: a bug ([#12](../../pull/12))
+* [00000000](../../commit/0000000000000000000000000000000000000000) docs: readme
+`, out)
+
+	out, err = changelog.Render(changelog.DefaultTemplate, changelog.Release{Version: "1.0.1", Date: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)})
+	a.NoError(err)
+	a.Equal("## 1.0.1 2020-01-02\n", out)
+}
+
+func TestRenderDefaultTemplateWithForge(t *testing.T) {
+	a := assert.New(t)
+	f, err := forge.FromRemote("git@gitlab.com:group/repo.git", "")
+	a.NoError(err)
+	r := testRelease(f)
+	r.Commits = r.Commits[:1]
+	r.CompareURL = f.CompareURL("v0.9.0", "v1.0.0")
+
+	out, err := changelog.Render(changelog.DefaultTemplate, r)
+	a.NoError(err)
+	a.Equal(`## [1.0.0](https://gitlab.com/group/repo/-/compare/v0.9.0...v1.0.0) 2020-01-02
+
+### Fixes/Other
+
+* [33333333](https://gitlab.com/group/repo/-/commit/3333333333333333333333333333333333333333) fix: a bug ([!12](https://gitlab.com/group/repo/-/merge_requests/12))
+`, out)
+	a.Equal("1.0.0", changelog.Parse(out).Sections[0].Version)
+}
+
+func TestRenderCommitLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"one-line message", "A commit message",
+			"* [00010203](../../commit/0001020304050607080900010203040506070809) A commit message\n"},
+		{"multiple line message", "A commit message\nWith multiple lines\nLast line.",
+			"* [00010203](../../commit/0001020304050607080900010203040506070809) A commit message\n"},
+		{"pull request number", "A commit message (#100)",
+			"* [00010203](../../commit/0001020304050607080900010203040506070809) A commit message ([#100](../../pull/100))\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			commit := changelog.NewCommit(&object.Commit{
+				Hash:    plumbing.Hash{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+				Message: tt.message,
+			}, commits.DefaultClassifier(), forge.Forge{}, nil)
+
+			out, err := changelog.Render(changelog.DefaultTemplate, changelog.Release{
+				Version: "1.0.0",
+				Date:    time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				Commits: []changelog.Commit{commit},
+			})
+			a.NoError(err)
+			a.Equal("## 1.0.0 2020-01-02\n\n### Fixes/Other\n\n"+tt.want, out)
+		})
+	}
+}
+
+func TestRenderCustomTemplate(t *testing.T) {
+	a := assert.New(t)
+
+	out, err := changelog.Render(`## [{{.Version}}] - {{.Date.Format "2006-01-02"}} (since {{.PreviousVersion}})
+{{range .Commits}}
+- {{.Subject}} ({{.Class}}, {{.Author}}{{if .PR}}, #{{.PR}}{{end}})
+{{- end}}
+`, testRelease(forge.Forge{}))
+	a.NoError(err)
+	a.Equal(`## [1.0.0] - 2020-01-02 (since 0.9.0)
+
+- fix: a bug (fix, Jane Doe, #12)
+- feat!: drop the old API (breaking, Jane Doe)
+- [feature] new API (feature, Jane Doe)
+- docs: readme (other, Jane Doe)
+`, out)
+
+	_, err = changelog.Render("{{.Version", testRelease(forge.Forge{}))
+	a.Error(err)
+	_, err = changelog.Render("{{.Nope}}", testRelease(forge.Forge{}))
+	a.Error(err)
+}
+
+func TestEncodeRelease(t *testing.T) {
+	a := assert.New(t)
+	r := testRelease(forge.Forge{})
+	r.PreviousTag = "v0.9.0"
+	r.Commits = r.Commits[:1]
+
+	out, err := json.Marshal(r)
+	a.NoError(err)
+	a.JSONEq(`{
+		"version": "1.0.0",
+		"previous_version": "0.9.0",
+		"previous_tag": "v0.9.0",
+		"date": "2020-01-02T00:00:00Z",
+		"commits": [{
+			"hash": "3333333333333333333333333333333333333333",
+			"subject": "fix: a bug",
+			"author": "Jane Doe",
+			"author_email": "jane@example.com",
+			"pr": 12,
+			"class": "fix",
+			"url": "../../commit/3333333333333333333333333333333333333333",
+			"pr_url": "../../pull/12"
+		}]
+	}`, string(out))
+
+	out, err = yaml.Marshal(r)
+	a.NoError(err)
+	a.Contains(string(out), "previous_tag: v0.9.0\n")
+	a.Contains(string(out), "  class: fix\n")
+}
+
+func TestContributors(t *testing.T) {
+	a := assert.New(t)
+	m, err := mailmap.Parse("Jane Doe <jane@example.com> <jdoe@old.example.com>\n")
+	a.NoError(err)
+
+	c := changelog.NewCommit(&object.Commit{
+		Hash: plumbing.NewHash("4444444444444444444444444444444444444444"),
+		Message: `fix: pair on the bug
+
+Co-authored-by: Octo Cat <583231+octocat@users.noreply.github.com>
+Co-authored-by: jdoe <jdoe@old.example.com>
+co-authored-by: Joe Smith <joe@example.com>
+Signed-off-by: Jane Doe <jane@example.com>
+`,
+		Author: object.Signature{Name: "jdoe", Email: "JDoe@old.example.com"},
+	}, commits.DefaultClassifier(), forge.Forge{}, m)
+
+	a.Equal("Jane Doe", c.Author)
+	a.Equal("jane@example.com", c.AuthorEmail)
+	a.Equal([]changelog.Contributor{
+		{Name: "Octo Cat", Email: "583231+octocat@users.noreply.github.com", Handle: "octocat"},
+		{Name: "Jane Doe", Email: "jane@example.com"},
+		{Name: "Joe Smith", Email: "joe@example.com"},
+	}, c.CoAuthors)
+	a.Len(c.Contributors(), 3)
+	a.Equal("Jane Doe, @octocat and Joe Smith", c.Mentions())
+
+	c.CoAuthors = nil
+	a.Equal("Jane Doe", c.Mentions())
+}
+
+func TestRenderAttribution(t *testing.T) {
+	a := assert.New(t)
+	r := testRelease(forge.Forge{})
+	r.Commits = r.Commits[:1]
+	r.Attribution = true
+	r.NewContributors = []changelog.Contributor{{Name: "Jane Doe", Email: "jane@example.com"}, {Name: "Octo Cat", Handle: "octocat"}}
+
+	out, err := changelog.Render(changelog.DefaultTemplate, r)
+	a.NoError(err)
+	a.Equal(`## 1.0.0 2020-01-02
+
+### Fixes/Other
+
+* [33333333](../../commit/3333333333333333333333333333333333333333) fix: a bug ([#12](../../pull/12)) by Jane Doe
+
+### New Contributors
+
+* Jane Doe
+* @octocat
+`, out)
+}
@@ -0,0 +1,85 @@
+package commits
+
+import "strings"
+
+// Class describes how a commit should move the version
+type Class int
+
+const (
+	// Other is any commit that is not a fix, feature or breaking change
+	Other Class = iota
+	// Fix is a conventional `fix:` commit
+	Fix
+	// Feature is a conventional `feat:` commit or one marked with a feature marker
+	Feature
+	// Breaking is a breaking change, marked with `!`, a BREAKING CHANGE footer or a breaking marker
+	Breaking
+)
+
+func (c Class) String() string {
+	switch c {
+	case Breaking:
+		return "breaking"
+	case Feature:
+		return "feature"
+	case Fix:
+		return "fix"
+	default:
+		return "other"
+	}
+}
+
+// MarshalText encodes the class as its name, e.g. in the json or yaml changelog output
+func (c Class) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+var (
+	// DefaultBreakingMarkers are the substrings that flag a commit as breaking
+	DefaultBreakingMarkers = []string{"[breaking]"}
+	// DefaultFeatureMarkers are the substrings that flag a commit as a feature
+	DefaultFeatureMarkers = []string{"[feature]"}
+)
+
+// Classifier sorts commit messages into classes using both the bracket markers
+// and conventional commit headers/footers
+type Classifier struct {
+	BreakingMarkers []string
+	FeatureMarkers  []string
+}
+
+// DefaultClassifier returns a classifier with the default markers
+func DefaultClassifier() Classifier {
+	return Classifier{
+		BreakingMarkers: DefaultBreakingMarkers,
+		FeatureMarkers:  DefaultFeatureMarkers,
+	}
+}
+
+// Classify returns the class of a raw commit message
+func (c Classifier) Classify(message string) Class {
+	return c.ClassifyMessage(message, Parse(message))
+}
+
+// ClassifyMessage returns the class of an already parsed commit message
+func (c Classifier) ClassifyMessage(raw string, m Message) Class {
+	if m.Breaking || containsAny(raw, c.BreakingMarkers) {
+		return Breaking
+	}
+	if m.Type == "feat" || m.Type == "feature" || containsAny(raw, c.FeatureMarkers) {
+		return Feature
+	}
+	if m.Type == "fix" {
+		return Fix
+	}
+	return Other
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if marker != "" && strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
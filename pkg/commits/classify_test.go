@@ -0,0 +1,32 @@
+package commits_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/commits"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    commits.Class
+	}{
+		{"plain", "A commit message", commits.Other},
+		{"feature marker", "A commit message [feature]", commits.Feature},
+		{"breaking marker", "[breaking] A commit message", commits.Breaking},
+		{"conventional fix", "fix(api): handle nil", commits.Fix},
+		{"conventional feat", "feat: add bump", commits.Feature},
+		{"conventional breaking", "feat!: drop flag", commits.Breaking},
+		{"breaking footer", "chore: deps\n\nBREAKING CHANGE: requires go 1.14", commits.Breaking},
+		{"other conventional type", "docs: readme", commits.Other},
+	}
+	c := commits.DefaultClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Classify(tt.message); got != tt.want {
+				t.Errorf("Classify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
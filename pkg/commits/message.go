@@ -0,0 +1,109 @@
+package commits
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// type(scope)!: description
+	headerRegexp = regexp.MustCompile(`^([A-Za-z][\w-]*)(?:\(([^()\r\n]*)\))?(!)?: (.*\S.*)$`)
+	// Token: value or Token #value, see https://git-scm.com/docs/git-interpret-trailers
+	footerRegexp = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[A-Za-z][\w-]*)(: | #)(.*)$`)
+)
+
+// Footer is a single `Token: value` trailer found at the end of a commit message
+type Footer struct {
+	Token string
+	Value string
+}
+
+// Message is a parsed commit message. Messages that do not follow
+// https://www.conventionalcommits.org have Conventional set to false and no Type, Scope or Description.
+type Message struct {
+	// Subject is the first line of the commit message
+	Subject string
+	// Body is everything after the subject, excluding footers
+	Body    string
+	Footers []Footer
+
+	Conventional bool
+	Type         string
+	Scope        string
+	Description  string
+	// Breaking is true if the header has a `!` marker or there is a BREAKING CHANGE footer
+	Breaking bool
+}
+
+// Parse splits a commit message into its conventional commit parts
+func Parse(message string) Message {
+	message = strings.TrimSpace(strings.Replace(message, "\r\n", "\n", -1))
+	lines := strings.Split(message, "\n")
+
+	m := Message{
+		Subject: strings.TrimSpace(lines[0]),
+		Body:    strings.TrimSpace(strings.Join(lines[1:], "\n")),
+	}
+
+	m.Body, m.Footers = splitFooters(m.Body)
+	for _, f := range m.Footers {
+		if f.Token == "BREAKING CHANGE" || f.Token == "BREAKING-CHANGE" {
+			m.Breaking = true
+		}
+	}
+
+	header := headerRegexp.FindStringSubmatch(m.Subject)
+	if header == nil {
+		return m
+	}
+	m.Conventional = true
+	m.Type = strings.ToLower(header[1])
+	m.Scope = strings.TrimSpace(header[2])
+	m.Breaking = m.Breaking || header[3] == "!"
+	m.Description = strings.TrimSpace(header[4])
+	return m
+}
+
+// Footer returns the value of the first footer with the given token (case-insensitive)
+func (m Message) Footer(token string) (string, bool) {
+	for _, f := range m.Footers {
+		if strings.EqualFold(f.Token, token) {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// splitFooters separates the trailing footer paragraph from the rest of the body.
+// A paragraph is only treated as footers if its first line looks like a footer.
+func splitFooters(body string) (string, []Footer) {
+	if body == "" {
+		return "", nil
+	}
+	paragraphs := strings.Split(body, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	lines := strings.Split(last, "\n")
+	if !footerRegexp.MatchString(lines[0]) {
+		return body, nil
+	}
+
+	footers := []Footer{}
+	for _, line := range lines {
+		match := footerRegexp.FindStringSubmatch(line)
+		if match == nil {
+			// continuation of a multi-line footer value
+			footers[len(footers)-1].Value += "\n" + line
+			continue
+		}
+		value := match[3]
+		if match[2] == " #" {
+			value = "#" + value
+		}
+		footers = append(footers, Footer{Token: match[1], Value: value})
+	}
+	for i := range footers {
+		footers[i].Value = strings.TrimSpace(footers[i].Value)
+	}
+
+	return strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n")), footers
+}
@@ -0,0 +1,83 @@
+package commits_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/commits"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    commits.Message
+	}{
+		{"plain message",
+			"A commit message\n\nWith a body",
+			commits.Message{Subject: "A commit message", Body: "With a body"},
+		},
+		{"type only",
+			"fix: handle empty tags",
+			commits.Message{Subject: "fix: handle empty tags", Conventional: true, Type: "fix", Description: "handle empty tags"},
+		},
+		{"type and scope",
+			"feat(api): add endpoint",
+			commits.Message{Subject: "feat(api): add endpoint", Conventional: true, Type: "feat", Scope: "api", Description: "add endpoint"},
+		},
+		{"breaking marker",
+			"feat(api)!: drop v1",
+			commits.Message{Subject: "feat(api)!: drop v1", Conventional: true, Type: "feat", Scope: "api", Description: "drop v1", Breaking: true},
+		},
+		{"breaking footer",
+			"refactor: rename config\n\nSome details.\n\nBREAKING CHANGE: `foo` is now `bar`\nReviewed-by: Z",
+			commits.Message{
+				Subject:      "refactor: rename config",
+				Body:         "Some details.",
+				Conventional: true,
+				Type:         "refactor",
+				Description:  "rename config",
+				Breaking:     true,
+				Footers: []commits.Footer{
+					{Token: "BREAKING CHANGE", Value: "`foo` is now `bar`"},
+					{Token: "Reviewed-by", Value: "Z"},
+				},
+			},
+		},
+		{"hyphenated breaking footer, multi-line value",
+			"fix: x\n\nBREAKING-CHANGE: first\n  second",
+			commits.Message{
+				Subject:      "fix: x",
+				Conventional: true,
+				Type:         "fix",
+				Description:  "x",
+				Breaking:     true,
+				Footers:      []commits.Footer{{Token: "BREAKING-CHANGE", Value: "first\n  second"}},
+			},
+		},
+		{"hash footer on non-conventional message",
+			"Update readme\n\nFixes #12",
+			commits.Message{Subject: "Update readme", Footers: []commits.Footer{{Token: "Fixes", Value: "#12"}}},
+		},
+		{"missing description is not conventional",
+			"feat: ",
+			commits.Message{Subject: "feat:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, commits.Parse(tt.message))
+		})
+	}
+}
+
+func TestMessageFooter(t *testing.T) {
+	a := assert.New(t)
+	m := commits.Parse("fix: x\n\nCo-authored-by: A <a@example.com>")
+	v, ok := m.Footer("co-authored-by")
+	a.True(ok)
+	a.Equal("A <a@example.com>", v)
+
+	_, ok = m.Footer("Signed-off-by")
+	a.False(ok)
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Component is a separately versioned part of a repo, with its own version file, tags and changelog
+type Component struct {
+	// Name identifies the component, it is empty for the whole repo
+	Name string `yaml:"name" json:"name"`
+	// Paths are the directories (or files) of the component, only commits touching them are considered.
+	// No paths means the whole repo.
+	Paths []string `yaml:"paths" json:"paths"`
+	// VersionFile defaults to VERSION in the component's first path
+	VersionFile string `yaml:"version_file" json:"version_file"`
+	// VersionFiles are other files of the component holding its version
+	VersionFiles []VersionFile `yaml:"version_files" json:"version_files"`
+	// TagPrefix defaults to the component's name followed by /v, e.g. api/v
+	TagPrefix string `yaml:"tag_prefix" json:"tag_prefix"`
+	// ChangelogFile defaults to CHANGELOG.md in the component's first path
+	ChangelogFile string `yaml:"changelog_file" json:"changelog_file"`
+}
+
+// Group is a fixed set of components that are released in lockstep: they share one version,
+// the release type is the biggest across all members' commits and every member is bumped in a single commit
+type Group struct {
+	Name       string   `yaml:"name" json:"name"`
+	Components []string `yaml:"components" json:"components"`
+}
+
+// VersionFile is a file other than the version file that also holds the version, such as package.json,
+// Chart.yaml, pyproject.toml, Cargo.toml or a Go source file. Only the version is rewritten on release.
+type VersionFile struct {
+	Path string `yaml:"path" json:"path"`
+	// Format is one of plain, json, yaml, toml or go, it is inferred from the file extension if empty
+	Format string `yaml:"format" json:"format,omitempty"`
+	// Keys are where the version is in the file, e.g. version and appVersion in a Chart.yaml,
+	// package.version in a Cargo.toml or the constant name in a Go file. Each format has a sensible default.
+	Keys []string `yaml:"keys" json:"keys,omitempty"`
+}
+
+// Root returns the whole repo as a component, using the top level settings
+func (c *Config) Root() Component {
+	return Component{
+		VersionFile:   c.VersionFile,
+		VersionFiles:  c.VersionFiles,
+		TagPrefix:     c.TagPrefix,
+		ChangelogFile: c.Changelog.File,
+	}
+}
+
+// Component returns the configured component with the given name, with defaults filled in
+func (c *Config) Component(name string) (Component, error) {
+	for _, component := range c.Components {
+		if component.Name == name {
+			return component.withDefaults()
+		}
+	}
+	return Component{}, errors.Errorf("unknown component %s", name)
+}
+
+// AllComponents returns every configured component, with defaults filled in
+func (c *Config) AllComponents() ([]Component, error) {
+	components := []Component{}
+	seen := map[string]bool{}
+	for _, component := range c.Components {
+		if seen[component.Name] {
+			return nil, errors.Errorf("component %s is declared more than once", component.Name)
+		}
+		seen[component.Name] = true
+
+		component, err := component.withDefaults()
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	grouped := map[string]string{}
+	for _, group := range c.Groups {
+		if group.Name == "" {
+			return nil, errors.New("groups must have a name")
+		}
+		if seen[group.Name] {
+			return nil, errors.Errorf("group %s has the same name as a component", group.Name)
+		}
+		if len(group.Components) == 0 {
+			return nil, errors.Errorf("group %s has no components", group.Name)
+		}
+		for _, name := range group.Components {
+			if !seen[name] {
+				return nil, errors.Errorf("group %s has unknown component %s", group.Name, name)
+			}
+			if other, ok := grouped[name]; ok {
+				return nil, errors.Errorf("component %s is in both group %s and group %s", name, other, group.Name)
+			}
+			grouped[name] = group.Name
+		}
+	}
+	return components, nil
+}
+
+// Group returns the group with the given name
+func (c *Config) Group(name string) (Group, bool) {
+	for _, group := range c.Groups {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return Group{}, false
+}
+
+// GroupOf returns the group the named component belongs to
+func (c *Config) GroupOf(component string) (Group, bool) {
+	for _, group := range c.Groups {
+		for _, member := range group.Components {
+			if member == component {
+				return group, true
+			}
+		}
+	}
+	return Group{}, false
+}
+
+func (c Component) withDefaults() (Component, error) {
+	if c.Name == "" {
+		return c, errors.New("components must have a name")
+	}
+	if len(c.Paths) == 0 {
+		return c, errors.Errorf("component %s must have at least one path", c.Name)
+	}
+	paths := []string{}
+	for _, p := range c.Paths {
+		paths = append(paths, path.Clean(p))
+	}
+	c.Paths = paths
+
+	if c.VersionFile == "" {
+		c.VersionFile = path.Join(c.Paths[0], "VERSION")
+	}
+	for _, f := range c.VersionFiles {
+		if f.Path == "" {
+			return c, errors.Errorf("component %s has a version file without a path", c.Name)
+		}
+	}
+	if c.TagPrefix == "" {
+		c.TagPrefix = fmt.Sprintf("%s/v", c.Name)
+	}
+	if c.ChangelogFile == "" {
+		c.ChangelogFile = path.Join(c.Paths[0], "CHANGELOG.md")
+	}
+	return c, nil
+}
+
+// Contains reports whether the file at filePath belongs to the component
+func (c Component) Contains(filePath string) bool {
+	if len(c.Paths) == 0 {
+		return true
+	}
+	filePath = path.Clean(filePath)
+	for _, p := range c.Paths {
+		if p == "." || filePath == p || strings.HasPrefix(filePath, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// TagName returns the tag name of the given version of the component
+func (c Component) TagName(version string) string {
+	return fmt.Sprintf("%s%s", c.TagPrefix, version)
+}
+
+// DisplayName returns the component's name, or "repo" for the whole repo
+func (c Component) DisplayName() string {
+	if c.Name == "" {
+		return "repo"
+	}
+	return c.Name
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponents(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "bff-config")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := writeConfig(t, dir, "repo.yaml", `
+components:
+  - name: api
+    paths: [services/api/, libs/proto]
+  - name: web
+    paths: [web]
+    version_file: web/package.json
+    tag_prefix: web-v
+    changelog_file: CHANGELOG-web.md
+`)
+	c, err := load([]file{{path: path}}, nil)
+	a.NoError(err)
+
+	api, err := c.Component("api")
+	a.NoError(err)
+	a.Equal(Component{
+		Name:          "api",
+		Paths:         []string{"services/api", "libs/proto"},
+		VersionFile:   "services/api/VERSION",
+		TagPrefix:     "api/v",
+		ChangelogFile: "services/api/CHANGELOG.md",
+	}, api)
+	a.Equal("api/v1.0.0", api.TagName("1.0.0"))
+
+	web, err := c.Component("web")
+	a.NoError(err)
+	a.Equal("web-v", web.TagPrefix)
+	a.Equal("CHANGELOG-web.md", web.ChangelogFile)
+
+	_, err = c.Component("worker")
+	a.Error(err)
+
+	all, err := c.AllComponents()
+	a.NoError(err)
+	a.Len(all, 2)
+
+	root := c.Root()
+	a.Equal("v", root.TagPrefix)
+	a.Equal("repo", root.DisplayName())
+}
+
+func TestComponentValidation(t *testing.T) {
+	a := assert.New(t)
+	c := Default()
+
+	c.Components = []Component{{Name: "api"}}
+	_, err := c.AllComponents()
+	a.Error(err)
+
+	c.Components = []Component{{Paths: []string{"api"}}}
+	_, err = c.AllComponents()
+	a.Error(err)
+
+	c.Components = []Component{{Name: "api", Paths: []string{"api"}}, {Name: "api", Paths: []string{"api2"}}}
+	_, err = c.AllComponents()
+	a.Error(err)
+}
+
+func TestComponentContains(t *testing.T) {
+	tests := []struct {
+		name      string
+		component Component
+		path      string
+		want      bool
+	}{
+		{"root contains everything", Component{}, "any/file.go", true},
+		{"file in directory", Component{Paths: []string{"services/api"}}, "services/api/main.go", true},
+		{"directory itself", Component{Paths: []string{"services/api"}}, "services/api", true},
+		{"sibling with common prefix", Component{Paths: []string{"services/api"}}, "services/api2/main.go", false},
+		{"outside", Component{Paths: []string{"services/api"}}, "web/index.js", false},
+		{"second path", Component{Paths: []string{"services/api", "libs/proto"}}, "libs/proto/api.proto", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.component.Contains(tt.path); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroups(t *testing.T) {
+	a := assert.New(t)
+	c := Default()
+	c.Components = []Component{
+		{Name: "client", Paths: []string{"client"}},
+		{Name: "server", Paths: []string{"server"}},
+		{Name: "web", Paths: []string{"web"}},
+	}
+	c.Groups = []Group{{Name: "sdk", Components: []string{"client", "server"}}}
+
+	_, err := c.AllComponents()
+	a.NoError(err)
+
+	group, ok := c.GroupOf("server")
+	a.True(ok)
+	a.Equal("sdk", group.Name)
+	_, ok = c.GroupOf("web")
+	a.False(ok)
+
+	group, ok = c.Group("sdk")
+	a.True(ok)
+	a.Equal([]string{"client", "server"}, group.Components)
+}
+
+func TestGroupValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []Group
+	}{
+		{"unnamed", []Group{{Components: []string{"client"}}}},
+		{"empty", []Group{{Name: "sdk"}}},
+		{"unknown component", []Group{{Name: "sdk", Components: []string{"worker"}}}},
+		{"component in two groups", []Group{{Name: "sdk", Components: []string{"client"}}, {Name: "libs", Components: []string{"client"}}}},
+		{"group named like a component", []Group{{Name: "client", Components: []string{"client"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			c.Components = []Component{{Name: "client", Paths: []string{"client"}}}
+			c.Groups = tt.groups
+			_, err := c.AllComponents()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVersionFiles(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "bff-config")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := writeConfig(t, dir, "repo.yaml", `
+version_files:
+  - path: package.json
+components:
+  - name: chart
+    paths: [charts/app]
+    version_files:
+      - path: charts/app/Chart.yaml
+        keys: [version, appVersion]
+  - name: broken
+    paths: [broken]
+    version_files:
+      - format: json
+`)
+	c, err := load([]file{{path: path}}, nil)
+	a.NoError(err)
+
+	a.Equal([]VersionFile{{Path: "package.json"}}, c.Root().VersionFiles)
+
+	chart, err := c.Component("chart")
+	a.NoError(err)
+	a.Equal([]VersionFile{{Path: "charts/app/Chart.yaml", Keys: []string{"version", "appVersion"}}}, chart.VersionFiles)
+
+	_, err = c.Component("broken")
+	a.Error(err)
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FileName is the name of both the repo-level and the user-level config file
+const FileName = ".bff.yaml"
+
+// EnvPrefix is the prefix of environment variables that override config settings,
+// e.g. BFF_TAG_PREFIX overrides tag_prefix and BFF_CHANGELOG_FILE overrides changelog.file
+const EnvPrefix = "BFF_"
+
+// SourceDefault is the source of settings that were not set anywhere
+const SourceDefault = "default"
+
+// Config is the effective bff configuration.
+//
+// Settings are layered, later layers win:
+//  1. built-in defaults
+//  2. the user-level file, ~/.bff.yaml
+//  3. the repo-level file, .bff.yaml in the current directory (or the file given with --config)
+//  4. BFF_* environment variables
+type Config struct {
+	// DefaultBranch is the branch releases are cut from. Empty means use the remote's HEAD.
+	DefaultBranch string `yaml:"default_branch"`
+	// Remote is the git remote bff fetches from
+	Remote string `yaml:"remote"`
+	// TagPrefix is prepended to the version to get the tag name
+	TagPrefix string `yaml:"tag_prefix"`
+	// VersionFile is the plain text file holding the current version
+	VersionFile string `yaml:"version_file"`
+	// VersionFiles are other files holding the version, e.g. package.json, updated along with the version file
+	VersionFiles []VersionFile `yaml:"version_files"`
+	// CommitMessage is a text/template for the release commit message, it receives .Component and .Version
+	CommitMessage string `yaml:"commit_message"`
+
+	Markers   Markers   `yaml:"markers"`
+	Changelog Changelog `yaml:"changelog"`
+	Tag       Tag       `yaml:"tag"`
+	Forge     Forge     `yaml:"forge"`
+	GitHub    GitHub    `yaml:"github"`
+
+	// Components are separately versioned parts of a monorepo
+	Components []Component `yaml:"components"`
+	// Groups are sets of components that are always released together, with the same version
+	Groups []Group `yaml:"groups"`
+
+	// sources maps each setting key to where its value came from
+	sources map[string]string
+}
+
+// Markers are the commit message substrings that flag a commit for a bigger release
+type Markers struct {
+	Breaking []string `yaml:"breaking"`
+	Feature  []string `yaml:"feature"`
+}
+
+// Changelog configures changelog generation
+type Changelog struct {
+	// File is the markdown file the changelog is written to
+	File string `yaml:"file"`
+	// Template is a text/template file rendering a release section, it receives a changelog.Release.
+	// Empty means the built-in layout.
+	Template string `yaml:"template"`
+	// Format is keepachangelog to release the Unreleased section of a keepachangelog.com changelog and keep its
+	// link references up to date. Empty means bff's own format.
+	Format string `yaml:"format"`
+	// Attribution credits the author and co-authors of each entry, and lists the new contributors of each release
+	Attribution bool `yaml:"attribution"`
+	// Issues link the references to issue trackers in commit messages
+	Issues []IssuePattern `yaml:"issues"`
+	// Include lists only the commits matching any of the rules, if there are any
+	Include []CommitRule `yaml:"include"`
+	// Exclude leaves out the commits matching any of the rules, even if they are included
+	Exclude []CommitRule `yaml:"exclude"`
+}
+
+// IssuePattern links the references to an issue tracker in commit messages
+type IssuePattern struct {
+	// Pattern is a regular expression matching a reference, e.g. JIRA-\d+. Its first group, if any, is the issue ID.
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// URL is a text/template for the link to an issue, it receives .ID and .Ref
+	URL string `yaml:"url" json:"url"`
+}
+
+// CommitRule matches commits listed in the changelog, every field that is set must match
+type CommitRule struct {
+	// Subject is a regular expression matching the first line of the message
+	Subject string `yaml:"subject" json:"subject,omitempty"`
+	// Author is a regular expression matching the author as "Name <email>"
+	Author string `yaml:"author" json:"author,omitempty"`
+	// Merge matches merge commits if true and other commits if false
+	Merge *bool `yaml:"merge" json:"merge,omitempty"`
+	// Paths match the commits that only change files in them, they are files, directories or globs
+	Paths []string `yaml:"paths" json:"paths,omitempty"`
+}
+
+// Forge configures the links to the forge hosting the repo, in the changelog
+type Forge struct {
+	// URL is the web URL of the repo, e.g. https://github.com/org/repo. Empty means derive it from the remote.
+	URL string `yaml:"url"`
+	// Type is github, gitlab, bitbucket or gitea. Empty means infer it from the host.
+	Type string `yaml:"type"`
+}
+
+// GitHub configures the GitHub releases
+type GitHub struct {
+	// Release publishes a GitHub release, with the release notes, for every tag pushed with --push
+	Release bool `yaml:"release"`
+	// APIURL is the root of the REST API. Empty means https://api.github.com for github.com, and
+	// https://<host>/api/v3 for GitHub Enterprise.
+	APIURL string `yaml:"api_url"`
+}
+
+// GitHubTokenEnv is the environment variable holding the token GitHub releases are published with
+const GitHubTokenEnv = "GITHUB_TOKEN"
+
+// Tag configures the release tags
+type Tag struct {
+	// Annotated creates annotated tags, with the release notes as their message, instead of lightweight tags
+	Annotated bool `yaml:"annotated"`
+	// Sign signs the tags with an OpenPGP key, it implies Annotated
+	Sign bool `yaml:"sign"`
+	// Keyring is the OpenPGP keyring file holding the signing key, armored or binary
+	Keyring string `yaml:"keyring"`
+	// SigningKey selects the key in the keyring by ID or user ID, the first private key is used if empty
+	SigningKey string `yaml:"signing_key"`
+}
+
+// SigningPassphraseEnv is the environment variable holding the passphrase of an encrypted signing key.
+// It is deliberately not a setting so that it never ends up in a config file.
+const SigningPassphraseEnv = "BFF_SIGNING_PASSPHRASE"
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		Remote:        "origin",
+		TagPrefix:     "v",
+		VersionFile:   "VERSION",
+		CommitMessage: "release {{if .Component}}{{.Component}} {{end}}version {{.Version}}",
+		Markers: Markers{
+			Breaking: []string{"[breaking]"},
+			Feature:  []string{"[feature]"},
+		},
+		Changelog: Changelog{
+			File: "CHANGELOG.md",
+		},
+	}
+}
+
+// Load builds the effective configuration. If path is empty .bff.yaml in the
+// current directory is used if it exists, otherwise the file at path must exist.
+func Load(path string) (*Config, error) {
+	files := []file{}
+
+	home, err := os.UserHomeDir()
+	if err == nil {
+		files = append(files, file{path: filepath.Join(home, FileName)})
+	}
+
+	if path == "" {
+		files = append(files, file{path: FileName})
+	} else {
+		files = append(files, file{path: path, required: true})
+	}
+
+	return load(files, os.Environ())
+}
+
+type file struct {
+	path     string
+	required bool
+}
+
+func load(files []file, environ []string) (*Config, error) {
+	c := Default()
+	c.sources = map[string]string{}
+
+	for _, f := range files {
+		err := c.mergeFile(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := c.mergeEnv(environ)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) mergeFile(f file) error {
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) && !f.required {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "unable to read config file %s", f.path)
+	}
+
+	err = yaml.UnmarshalStrict(data, c)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse config file %s", f.path)
+	}
+
+	raw := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse config file %s", f.path)
+	}
+	for _, s := range c.settings() {
+		if hasKey(raw, s.key) {
+			c.sources[s.key] = f.path
+		}
+	}
+	return nil
+}
+
+// Source returns where the setting with the given key, e.g. `changelog.file`, came from
+func (c *Config) Source(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// RenderCommitMessage renders the release commit message for the given component (empty for the whole repo) and version
+func (c *Config) RenderCommitMessage(component string, version string) (string, error) {
+	t, err := template.New("commit_message").Parse(c.CommitMessage)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to parse commit_message template")
+	}
+	buf := bytes.NewBuffer(nil)
+	err = t.Execute(buf, struct{ Component, Version string }{component, version})
+	if err != nil {
+		return "", errors.Wrap(err, "unable to render commit_message template")
+	}
+	return buf.String(), nil
+}
+
+// DefaultBranchRef returns the remote-tracking reference of the configured
+// default branch, or an empty string if none is configured
+func (c *Config) DefaultBranchRef() string {
+	if c.DefaultBranch == "" {
+		return ""
+	}
+	return fmt.Sprintf("refs/remotes/%s/%s", c.Remote, c.DefaultBranch)
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	a := assert.New(t)
+	c, err := load([]file{{path: "does-not-exist.yaml"}}, nil)
+	a.NoError(err)
+	a.Equal("v", c.TagPrefix)
+	a.Equal("VERSION", c.VersionFile)
+	a.Equal("CHANGELOG.md", c.Changelog.File)
+	a.Equal([]string{"[breaking]"}, c.Markers.Breaking)
+	a.Equal(SourceDefault, c.Source("tag_prefix"))
+}
+
+func TestLoadRequiredFileMissing(t *testing.T) {
+	_, err := load([]file{{path: "does-not-exist.yaml", required: true}}, nil)
+	assert.Error(t, err)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "bff-config")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+
+	user := writeConfig(t, dir, "user.yaml", `
+tag_prefix: release-
+version_file: VERSION.txt
+markers:
+  feature: ["[feat]"]
+`)
+	repo := writeConfig(t, dir, "repo.yaml", `
+tag_prefix: v
+changelog:
+  file: docs/CHANGELOG.md
+`)
+
+	c, err := load(
+		[]file{{path: user}, {path: repo}},
+		[]string{"BFF_VERSION_FILE=version", "BFF_MARKERS_BREAKING=[major], [break]", "HOME=/root"},
+	)
+	a.NoError(err)
+
+	a.Equal("v", c.TagPrefix)
+	a.Equal(repo, c.Source("tag_prefix"))
+
+	a.Equal("version", c.VersionFile)
+	a.Equal("env BFF_VERSION_FILE", c.Source("version_file"))
+
+	a.Equal([]string{"[feat]"}, c.Markers.Feature)
+	a.Equal(user, c.Source("markers.feature"))
+
+	a.Equal([]string{"[major]", "[break]"}, c.Markers.Breaking)
+	a.Equal("env BFF_MARKERS_BREAKING", c.Source("markers.breaking"))
+
+	a.Equal("docs/CHANGELOG.md", c.Changelog.File)
+	a.Equal(repo, c.Source("changelog.file"))
+
+	a.Equal(Default().CommitMessage, c.CommitMessage)
+	a.Equal(SourceDefault, c.Source("commit_message"))
+}
+
+func TestLoadUnknownKey(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "bff-config")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := writeConfig(t, dir, "repo.yaml", "tag_prefx: v\n")
+	_, err = load([]file{{path: path}}, nil)
+	a.Error(err)
+}
+
+func TestSettings(t *testing.T) {
+	a := assert.New(t)
+	c, err := load(nil, []string{"BFF_TAG_PREFIX=release-"})
+	a.NoError(err)
+
+	settings, err := c.Settings()
+	a.NoError(err)
+	a.Contains(settings, Setting{Key: "tag_prefix", Value: `"release-"`, Source: "env BFF_TAG_PREFIX"})
+	a.Contains(settings, Setting{Key: "changelog.file", Value: `"CHANGELOG.md"`, Source: SourceDefault})
+	a.Contains(settings, Setting{Key: "markers.feature", Value: `["[feature]"]`, Source: SourceDefault})
+}
+
+func TestRenderCommitMessage(t *testing.T) {
+	a := assert.New(t)
+	c := Default()
+	msg, err := c.RenderCommitMessage("", "1.2.3")
+	a.NoError(err)
+	a.Equal("release version 1.2.3", msg)
+
+	msg, err = c.RenderCommitMessage("api", "1.2.3")
+	a.NoError(err)
+	a.Equal("release api version 1.2.3", msg)
+
+	c.CommitMessage = "chore: release {{.Version"
+	_, err = c.RenderCommitMessage("", "1.2.3")
+	a.Error(err)
+}
+
+func TestDefaultBranchRef(t *testing.T) {
+	a := assert.New(t)
+	c := Default()
+	a.Equal("", c.DefaultBranchRef())
+	c.DefaultBranch = "main"
+	a.Equal("refs/remotes/origin/main", c.DefaultBranchRef())
+}
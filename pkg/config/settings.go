@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Setting is a single effective configuration value
+type Setting struct {
+	// Key is the dotted yaml path of the setting, e.g. changelog.file
+	Key string
+	// Value is the JSON encoding of the effective value
+	Value string
+	// Source is where the value came from: default, a file path or an environment variable
+	Source string
+}
+
+// Settings returns every setting with its effective value and source
+func (c *Config) Settings() ([]Setting, error) {
+	settings := []Setting{}
+	for _, s := range c.settings() {
+		value, err := json.Marshal(s.value.Interface())
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to encode setting %s", s.key)
+		}
+		settings = append(settings, Setting{
+			Key:    s.key,
+			Value:  string(value),
+			Source: c.Source(s.key),
+		})
+	}
+	return settings, nil
+}
+
+// EnvName returns the environment variable that overrides the setting with the given key
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+type setting struct {
+	key   string
+	value reflect.Value
+}
+
+// settings walks the config struct and returns its leaves. Nested structs are
+// walked into, anything else (including lists of structs) is a single setting.
+func (c *Config) settings() []setting {
+	return leaves("", reflect.ValueOf(c).Elem())
+}
+
+func leaves(prefix string, v reflect.Value) []setting {
+	result := []setting{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		if field.Type.Kind() == reflect.Struct {
+			result = append(result, leaves(key+".", v.Field(i))...)
+			continue
+		}
+		result = append(result, setting{key: key, value: v.Field(i)})
+	}
+	return result
+}
+
+func (c *Config) mergeEnv(environ []string) error {
+	env := map[string]string{}
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 && strings.HasPrefix(parts[0], EnvPrefix) {
+			env[parts[0]] = parts[1]
+		}
+	}
+
+	for _, s := range c.settings() {
+		name := EnvName(s.key)
+		raw, ok := env[name]
+		if !ok {
+			continue
+		}
+		err := setFromString(s.value, raw)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for %s", name)
+		}
+		c.sources[s.key] = "env " + name
+	}
+	return nil
+}
+
+// setFromString sets v from an environment variable value. Strings are taken
+// verbatim, lists of strings are comma separated and anything else is parsed as yaml.
+func setFromString(v reflect.Value, raw string) error {
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+		return nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		items := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				items = reflect.Append(items, reflect.ValueOf(item).Convert(v.Type().Elem()))
+			}
+		}
+		v.Set(items)
+		return nil
+	default:
+		ptr := reflect.New(v.Type())
+		err := yaml.UnmarshalStrict([]byte(raw), ptr.Interface())
+		if err != nil {
+			return err
+		}
+		v.Set(ptr.Elem())
+		return nil
+	}
+}
+
+// hasKey reports whether the dotted key is set in a decoded yaml document
+func hasKey(raw map[string]interface{}, key string) bool {
+	parts := strings.Split(key, ".")
+	var current interface{} = raw
+	for _, part := range parts {
+		switch m := current.(type) {
+		case map[string]interface{}:
+			next, ok := m[part]
+			if !ok {
+				return false
+			}
+			current = next
+		case map[interface{}]interface{}:
+			next, ok := m[part]
+			if !ok {
+				return false
+			}
+			current = next
+		default:
+			return false
+		}
+	}
+	return true
+}
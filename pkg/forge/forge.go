@@ -0,0 +1,167 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Types of forges
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Bitbucket = "bitbucket"
+	Gitea     = "gitea"
+)
+
+// scp-like SSH remotes, e.g. git@github.com:org/repo.git
+var scpRegexp = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// Forge builds the web links of a repo hosted on a forge. The zero value builds links relative to
+// a file at the root of a GitHub repo, e.g. ../../commit/<hash>.
+type Forge struct {
+	Type string
+	// URL is the web URL of the repo, e.g. https://github.com/org/repo
+	URL string
+}
+
+// New returns the forge of the repo with the web URL repoURL. The type is inferred from the host if empty.
+func New(repoURL string, forgeType string) (Forge, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return Forge{}, errors.Errorf("invalid repo URL %s", repoURL)
+	}
+	if forgeType == "" {
+		forgeType, err = inferType(u.Hostname())
+		if err != nil {
+			return Forge{}, err
+		}
+	}
+	switch forgeType {
+	case GitHub, GitLab, Bitbucket, Gitea:
+	default:
+		return Forge{}, errors.Errorf("unknown forge type %s, must be one of github, gitlab, bitbucket or gitea", forgeType)
+	}
+	return Forge{Type: forgeType, URL: strings.TrimSuffix(repoURL, "/")}, nil
+}
+
+// FromRemote returns the forge of the repo with the git remote URL remoteURL, over SSH or HTTPS.
+// The type is inferred from the host if empty.
+func FromRemote(remoteURL string, forgeType string) (Forge, error) {
+	webURL, err := WebURL(remoteURL)
+	if err != nil {
+		return Forge{}, err
+	}
+	return New(webURL, forgeType)
+}
+
+// WebURL returns the HTTPS URL of the repo with the git remote URL remoteURL, e.g.
+// https://github.com/org/repo for git@github.com:org/repo.git
+func WebURL(remoteURL string) (string, error) {
+	var host, repoPath string
+	if match := scpRegexp.FindStringSubmatch(remoteURL); match != nil && !strings.Contains(remoteURL, "://") {
+		host, repoPath = match[1], match[2]
+	} else {
+		u, err := url.Parse(remoteURL)
+		if err != nil || u.Host == "" {
+			return "", errors.Errorf("unable to find the host of remote %s", remoteURL)
+		}
+		switch u.Scheme {
+		case "ssh", "git", "git+ssh", "http", "https":
+		default:
+			return "", errors.Errorf("unsupported remote %s", remoteURL)
+		}
+		host, repoPath = u.Hostname(), u.Path
+		// the web UI is on the default port, except for plain HTTP(S) remotes on another port
+		if (u.Scheme == "http" || u.Scheme == "https") && u.Port() != "" {
+			host = u.Host
+		}
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	// Bitbucket Server clones over HTTPS from /scm/<project>/<repo>
+	repoPath = strings.TrimPrefix(repoPath, "scm/")
+	if repoPath == "" {
+		return "", errors.Errorf("unable to find the repo path of remote %s", remoteURL)
+	}
+	return fmt.Sprintf("https://%s/%s", host, repoPath), nil
+}
+
+func inferType(host string) (string, error) {
+	for _, t := range []string{GitHub, GitLab, Bitbucket, Gitea} {
+		if strings.Contains(host, t) {
+			return t, nil
+		}
+	}
+	return "", errors.Errorf("unable to tell which forge %s is, please configure forge.type", host)
+}
+
+// CommitURL returns the link to a commit
+func (f Forge) CommitURL(hash string) string {
+	switch f.Type {
+	case GitLab:
+		return fmt.Sprintf("%s/-/commit/%s", f.URL, hash)
+	case Bitbucket:
+		return fmt.Sprintf("%s/commits/%s", f.URL, hash)
+	case GitHub, Gitea:
+		return fmt.Sprintf("%s/commit/%s", f.URL, hash)
+	default:
+		return fmt.Sprintf("../../commit/%s", hash)
+	}
+}
+
+// PullRequestURL returns the link to a pull request, or merge request on GitLab
+func (f Forge) PullRequestURL(number int) string {
+	switch f.Type {
+	case GitLab:
+		return fmt.Sprintf("%s/-/merge_requests/%d", f.URL, number)
+	case Bitbucket:
+		return fmt.Sprintf("%s/pull-requests/%d", f.URL, number)
+	case Gitea:
+		return fmt.Sprintf("%s/pulls/%d", f.URL, number)
+	case GitHub:
+		return fmt.Sprintf("%s/pull/%d", f.URL, number)
+	default:
+		return fmt.Sprintf("../../pull/%d", number)
+	}
+}
+
+// PullRequestRef returns how the forge refers to a pull request in text, e.g. #12, or !12 for GitLab merge requests
+func (f Forge) PullRequestRef(number int) string {
+	if f.Type == GitLab {
+		return fmt.Sprintf("!%d", number)
+	}
+	return fmt.Sprintf("#%d", number)
+}
+
+// CompareURL returns the link to the changes between two refs, e.g. tags. It is empty for relative links.
+func (f Forge) CompareURL(from string, to string) string {
+	switch f.Type {
+	case GitLab:
+		return fmt.Sprintf("%s/-/compare/%s...%s", f.URL, from, to)
+	case Bitbucket:
+		return fmt.Sprintf("%s/branches/compare/%s%%0D%s", f.URL, to, from)
+	case GitHub, Gitea:
+		return fmt.Sprintf("%s/compare/%s...%s", f.URL, from, to)
+	default:
+		return ""
+	}
+}
+
+// TagURL returns the link to a tag, e.g. for a first release that has nothing to compare with.
+// It is empty for relative links.
+func (f Forge) TagURL(tag string) string {
+	switch f.Type {
+	case GitLab:
+		return fmt.Sprintf("%s/-/tags/%s", f.URL, tag)
+	case Bitbucket:
+		return fmt.Sprintf("%s/src/%s", f.URL, tag)
+	case GitHub, Gitea:
+		return fmt.Sprintf("%s/releases/tag/%s", f.URL, tag)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,114 @@
+package forge_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebURL(t *testing.T) {
+	var tests = []struct {
+		remote string
+		want   string
+	}{
+		{"git@github.com:chanzuckerberg/bff.git", "https://github.com/chanzuckerberg/bff"},
+		{"github.com:chanzuckerberg/bff", "https://github.com/chanzuckerberg/bff"},
+		{"https://github.com/chanzuckerberg/bff.git", "https://github.com/chanzuckerberg/bff"},
+		{"https://token@github.com/chanzuckerberg/bff", "https://github.com/chanzuckerberg/bff"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/project.git", "https://gitlab.example.com/group/sub/project"},
+		{"https://gitlab.example.com:8443/group/project.git", "https://gitlab.example.com:8443/group/project"},
+		{"git@bitbucket.org:team/repo.git", "https://bitbucket.org/team/repo"},
+		{"https://user@bitbucket.org/team/repo.git", "https://bitbucket.org/team/repo"},
+		{"git@gitea.com:org/repo.git", "https://gitea.com/org/repo"},
+	}
+	for _, test := range tests {
+		t.Run(test.remote, func(t *testing.T) {
+			got, err := forge.WebURL(test.remote)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	for _, remote := range []string{"/srv/git/repo.git", "file:///srv/git/repo.git", "git@github.com:"} {
+		_, err := forge.WebURL(remote)
+		assert.Error(t, err, remote)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	var tests = []struct {
+		remote  string
+		commit  string
+		pr      string
+		ref     string
+		compare string
+		tag     string
+	}{
+		{
+			"git@github.com:org/repo.git",
+			"https://github.com/org/repo/commit/abc",
+			"https://github.com/org/repo/pull/12",
+			"#12",
+			"https://github.com/org/repo/compare/v1.0.0...v1.1.0",
+			"https://github.com/org/repo/releases/tag/v1.1.0",
+		},
+		{
+			"git@gitlab.com:group/repo.git",
+			"https://gitlab.com/group/repo/-/commit/abc",
+			"https://gitlab.com/group/repo/-/merge_requests/12",
+			"!12",
+			"https://gitlab.com/group/repo/-/compare/v1.0.0...v1.1.0",
+			"https://gitlab.com/group/repo/-/tags/v1.1.0",
+		},
+		{
+			"git@bitbucket.org:team/repo.git",
+			"https://bitbucket.org/team/repo/commits/abc",
+			"https://bitbucket.org/team/repo/pull-requests/12",
+			"#12",
+			"https://bitbucket.org/team/repo/branches/compare/v1.1.0%0Dv1.0.0",
+			"https://bitbucket.org/team/repo/src/v1.1.0",
+		},
+		{
+			"https://gitea.com/org/repo.git",
+			"https://gitea.com/org/repo/commit/abc",
+			"https://gitea.com/org/repo/pulls/12",
+			"#12",
+			"https://gitea.com/org/repo/compare/v1.0.0...v1.1.0",
+			"https://gitea.com/org/repo/releases/tag/v1.1.0",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.remote, func(t *testing.T) {
+			a := assert.New(t)
+			f, err := forge.FromRemote(test.remote, "")
+			a.NoError(err)
+			a.Equal(test.commit, f.CommitURL("abc"))
+			a.Equal(test.pr, f.PullRequestURL(12))
+			a.Equal(test.ref, f.PullRequestRef(12))
+			a.Equal(test.compare, f.CompareURL("v1.0.0", "v1.1.0"))
+			a.Equal(test.tag, f.TagURL("v1.1.0"))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	a := assert.New(t)
+
+	f, err := forge.New("https://git.example.com/org/repo/", forge.Gitea)
+	a.NoError(err)
+	a.Equal("https://git.example.com/org/repo/commit/abc", f.CommitURL("abc"))
+
+	_, err = forge.New("https://git.example.com/org/repo", "")
+	a.Error(err)
+	_, err = forge.New("https://github.com/org/repo", "sourcehut")
+	a.Error(err)
+	_, err = forge.New("not a url", forge.GitHub)
+	a.Error(err)
+
+	relative := forge.Forge{}
+	a.Equal("../../commit/abc", relative.CommitURL("abc"))
+	a.Equal("../../pull/12", relative.PullRequestURL(12))
+	a.Equal("", relative.CompareURL("v1.0.0", "v1.1.0"))
+	a.Equal("", relative.TagURL("v1.1.0"))
+}
@@ -0,0 +1,148 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultAPIURL is the root of the REST API of github.com
+const DefaultAPIURL = "https://api.github.com"
+
+// Client creates releases with the GitHub REST API
+type Client struct {
+	// BaseURL is the root of the API, DefaultAPIURL or https://<host>/api/v3 for GitHub Enterprise
+	BaseURL string
+	// Token authenticates the requests, e.g. a personal access token or the GITHUB_TOKEN of an Actions workflow
+	Token      string
+	HTTPClient *http.Client
+}
+
+// Release is a GitHub release
+type Release struct {
+	ID         int64  `json:"id,omitempty"`
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	HTMLURL    string `json:"html_url,omitempty"`
+}
+
+// NewClient returns a client of the API at baseURL, DefaultAPIURL if empty, authenticated with token
+func NewClient(baseURL string, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// APIURL returns the root of the REST API of the GitHub instance serving the repo with the web URL repoURL
+func APIURL(repoURL string) (string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return "", errors.Errorf("invalid repo URL %s", repoURL)
+	}
+	if u.Host == "github.com" {
+		return DefaultAPIURL, nil
+	}
+	return fmt.Sprintf("%s://%s/api/v3", u.Scheme, u.Host), nil
+}
+
+// Repo returns the owner and name of the repo with the web URL repoURL, e.g. https://github.com/org/repo
+func Repo(repoURL string) (string, string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return "", "", errors.Errorf("invalid repo URL %s", repoURL)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("unable to find the owner and name of repo %s", repoURL)
+	}
+	return parts[0], parts[1], nil
+}
+
+// PublishRelease creates the release of release.TagName in the repo, or updates it if there is one already
+func (c *Client) PublishRelease(owner string, repo string, release Release) (*Release, error) {
+	existing := &Release{}
+	path := fmt.Sprintf("/repos/%s/%s/releases/tags/%s", owner, repo, url.PathEscape(release.TagName))
+	found, err := c.do(http.MethodGet, path, nil, existing)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to look up the release of %s", release.TagName)
+	}
+
+	published := &Release{}
+	if found {
+		path = fmt.Sprintf("/repos/%s/%s/releases/%d", owner, repo, existing.ID)
+		_, err = c.do(http.MethodPatch, path, release, published)
+		return published, errors.Wrapf(err, "unable to update the release of %s", release.TagName)
+	}
+	path = fmt.Sprintf("/repos/%s/%s/releases", owner, repo)
+	_, err = c.do(http.MethodPost, path, release, published)
+	return published, errors.Wrapf(err, "unable to create the release of %s", release.TagName)
+}
+
+// do sends a request with the JSON encoding of in, if not nil, and decodes the response into out. It returns false
+// without an error if the API responds with 404 Not Found.
+func (c *Client) do(method string, path string, in interface{}, out interface{}) (bool, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return false, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "bff")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodGet {
+		return false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := struct {
+			Message string `json:"message"`
+		}{}
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return false, errors.Errorf("%s %s: %s: %s", method, path, resp.Status, apiErr.Message)
+	}
+	return true, errors.Wrap(json.Unmarshal(data, out), "unable to decode the response")
+}
@@ -0,0 +1,121 @@
+package github_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/github"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGitHub is a stand-in for the releases API of a GitHub Enterprise instance, serving under /api/v3
+type fakeGitHub struct {
+	t        *testing.T
+	releases map[string]*github.Release
+	requests []string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	assert.Equal(f.t, "token secret", r.Header.Get("Authorization"))
+	assert.Equal(f.t, "application/vnd.github.v3+json", r.Header.Get("Accept"))
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v3/repos/org/repo/releases")
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/tags/"):
+		release, ok := f.releases[strings.TrimPrefix(path, "/tags/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+			return
+		}
+		assert.NoError(f.t, json.NewEncoder(w).Encode(release))
+	case r.Method == http.MethodPost && path == "":
+		release := &github.Release{}
+		assert.NoError(f.t, json.NewDecoder(r.Body).Decode(release))
+		if release.TagName == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Validation Failed"}`)
+			return
+		}
+		release.ID = int64(len(f.releases) + 1)
+		release.HTMLURL = "https://github.example.com/org/repo/releases/tag/" + release.TagName
+		f.releases[release.TagName] = release
+		w.WriteHeader(http.StatusCreated)
+		assert.NoError(f.t, json.NewEncoder(w).Encode(release))
+	case r.Method == http.MethodPatch:
+		for _, existing := range f.releases {
+			if path == fmt.Sprintf("/%d", existing.ID) {
+				assert.NoError(f.t, json.NewDecoder(r.Body).Decode(existing))
+				assert.NoError(f.t, json.NewEncoder(w).Encode(existing))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestPublishRelease(t *testing.T) {
+	a := assert.New(t)
+	fake := &fakeGitHub{t: t, releases: map[string]*github.Release{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client := github.NewClient(server.URL+"/api/v3/", "secret")
+
+	created, err := client.PublishRelease("org", "repo", github.Release{
+		TagName:    "v1.0.0-rc.1",
+		Name:       "v1.0.0-rc.1",
+		Body:       "* A feature",
+		Prerelease: true,
+	})
+	a.NoError(err)
+	a.Equal(int64(1), created.ID)
+	a.True(created.Prerelease)
+	a.Equal("https://github.example.com/org/repo/releases/tag/v1.0.0-rc.1", created.HTMLURL)
+
+	updated, err := client.PublishRelease("org", "repo", github.Release{
+		TagName: "v1.0.0-rc.1",
+		Name:    "v1.0.0-rc.1",
+		Body:    "* A feature\n* A fix",
+	})
+	a.NoError(err)
+	a.Equal(int64(1), updated.ID)
+	a.False(updated.Prerelease)
+	a.Equal("* A feature\n* A fix", fake.releases["v1.0.0-rc.1"].Body)
+	a.Len(fake.releases, 1)
+
+	a.Equal([]string{
+		"GET /api/v3/repos/org/repo/releases/tags/v1.0.0-rc.1",
+		"POST /api/v3/repos/org/repo/releases",
+		"GET /api/v3/repos/org/repo/releases/tags/v1.0.0-rc.1",
+		"PATCH /api/v3/repos/org/repo/releases/1",
+	}, fake.requests)
+
+	_, err = client.PublishRelease("org", "repo", github.Release{})
+	a.Error(err)
+	a.Contains(err.Error(), "Validation Failed")
+}
+
+func TestRepo(t *testing.T) {
+	a := assert.New(t)
+
+	owner, name, err := github.Repo("https://github.com/org/repo")
+	a.NoError(err)
+	a.Equal("org", owner)
+	a.Equal("repo", name)
+	_, _, err = github.Repo("https://github.com/org")
+	a.Error(err)
+
+	api, err := github.APIURL("https://github.com/org/repo")
+	a.NoError(err)
+	a.Equal(github.DefaultAPIURL, api)
+	api, err = github.APIURL("https://github.example.com/org/repo")
+	a.NoError(err)
+	a.Equal("https://github.example.com/api/v3", api)
+}
@@ -0,0 +1,97 @@
+package mailmap
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// A mailmap line is an optional name and email followed by an optional commit name and commit email,
+// e.g. "Jane Doe <jane@example.com> <jdoe@old.example.com>"
+var lineRegexp = regexp.MustCompile(`^([^<#]*)<([^>]*)>\s*(?:([^<#]*)<([^>]*)>)?\s*(?:#.*)?$`)
+
+// Mailmap maps the identities commits were made with to canonical ones, see
+// https://git-scm.com/docs/gitmailmap
+type Mailmap struct {
+	entries []entry
+}
+
+type entry struct {
+	name        string
+	email       string
+	commitName  string
+	commitEmail string
+}
+
+// Read parses the mailmap file at path. A missing file is an empty mailmap.
+func Read(path string) (*Mailmap, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Mailmap{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read %s", path)
+	}
+	return Parse(string(content))
+}
+
+// Parse parses the content of a mailmap file
+func Parse(content string) (*Mailmap, error) {
+	m := &Mailmap{}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		match := lineRegexp.FindStringSubmatch(line)
+		if match == nil {
+			return nil, errors.Errorf("invalid mailmap line %d: %s", i+1, line)
+		}
+		e := entry{
+			name:        strings.TrimSpace(match[1]),
+			email:       strings.TrimSpace(match[2]),
+			commitName:  strings.TrimSpace(match[3]),
+			commitEmail: strings.TrimSpace(match[4]),
+		}
+		if match[4] == "" && match[3] == "" {
+			// "Proper Name <commit@email>" only replaces the name
+			e.commitEmail, e.email = e.email, ""
+		}
+		m.entries = append(m.entries, e)
+	}
+	return m, nil
+}
+
+// Resolve returns the canonical name and email of an identity. Emails are compared case-insensitively. Like git,
+// an entry matching both the commit name and email wins over one matching the email only, and later entries win.
+func (m *Mailmap) Resolve(name string, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+	var best *entry
+	for i := range m.entries {
+		e := &m.entries[i]
+		if !strings.EqualFold(e.commitEmail, email) {
+			continue
+		}
+		if e.commitName != "" && e.commitName != name {
+			continue
+		}
+		if best == nil || e.commitName != "" || best.commitName == "" {
+			best = e
+		}
+	}
+	if best == nil {
+		return name, email
+	}
+	if best.name != "" {
+		name = best.name
+	}
+	if best.email != "" {
+		email = best.email
+	}
+	return name, email
+}
@@ -0,0 +1,47 @@
+package mailmap_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/mailmap"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMailmap = `# canonical identities
+Jane Doe <jane@example.com>
+<jane@example.com> <jdoe@old.example.com>
+Joe Smith <joe@example.com> <joe@laptop.local> # work laptop
+Joe Smith <joe@example.com> joe <shared@example.com>
+`
+
+func TestResolve(t *testing.T) {
+	m, err := mailmap.Parse(testMailmap)
+	assert.NoError(t, err)
+
+	var tests = []struct {
+		name, email         string
+		wantName, wantEmail string
+	}{
+		{"jane", "jane@example.com", "Jane Doe", "jane@example.com"},
+		{"jdoe", "JDoe@old.example.com", "jdoe", "jane@example.com"},
+		{"joe", "joe@laptop.local", "Joe Smith", "joe@example.com"},
+		{"joe", "shared@example.com", "Joe Smith", "joe@example.com"},
+		{"someone", "shared@example.com", "someone", "shared@example.com"},
+		{"Ann", "ann@example.com", "Ann", "ann@example.com"},
+	}
+	for _, test := range tests {
+		t.Run(test.email, func(t *testing.T) {
+			name, email := m.Resolve(test.name, test.email)
+			assert.Equal(t, test.wantName, name)
+			assert.Equal(t, test.wantEmail, email)
+		})
+	}
+
+	var empty *mailmap.Mailmap
+	name, email := empty.Resolve("Ann", "ann@example.com")
+	assert.Equal(t, "Ann", name)
+	assert.Equal(t, "ann@example.com", email)
+
+	_, err = mailmap.Parse("Jane Doe jane@example.com\n")
+	assert.Error(t, err)
+}
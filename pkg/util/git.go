@@ -1,6 +1,7 @@
 package util
 
 import (
+	"container/heap"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -22,6 +23,7 @@ type GitRepoIface interface {
 	Log(o *git.LogOptions) (object.CommitIter, error)
 	Reference(name plumbing.ReferenceName, resolved bool) (*plumbing.Reference, error)
 	Tags() (storer.ReferenceIter, error)
+	TagObject(h plumbing.Hash) (*object.Tag, error)
 }
 
 // GetGitAuthor returns the author name and email
@@ -43,39 +45,26 @@ func runCmd(cmd string, args []string) ([]byte, error) {
 	return execCommand(cmd, args...).Output()
 }
 
-// LatestTagCommitHash will get the latest tag and commit hash for a repo
-func LatestTagCommitHash(repo GitRepoIface, branchRef string) (*string, *plumbing.Hash, error) {
+// LatestTagCommitHash will get the latest tag and commit hash for a repo, considering only tags named tagPrefix + version
+// Pre-release and build tags are skipped.
+func LatestTagCommitHash(repo GitRepoIface, branchRef string, tagPrefix string) (*string, *plumbing.Hash, error) {
+	return latestTagCommitHash(repo, branchRef, tagPrefix, false)
+}
+
+// LatestTagCommitHashWithPreReleases is like LatestTagCommitHash, but also considers pre-release tags such as v1.4.0-rc.1
+func LatestTagCommitHashWithPreReleases(repo GitRepoIface, branchRef string, tagPrefix string) (*string, *plumbing.Hash, error) {
+	return latestTagCommitHash(repo, branchRef, tagPrefix, true)
+}
+
+func latestTagCommitHash(repo GitRepoIface, branchRef string, tagPrefix string, preReleases bool) (*string, *plumbing.Hash, error) {
 	branchCommit, err := VerifyDefaultBranch(repo, branchRef)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	tagIndex := make(map[string]string)
-
-	tags, err := repo.Tags()
+	tagIndex, err := VersionTags(repo, tagPrefix, preReleases)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "could not fetch repo tags")
-	}
-
-	err = tags.ForEach(func(tag *plumbing.Reference) error {
-		tagName := strings.Replace(tag.Name().String(), "refs/tags/v", "", -1)
-		version, err := semver.Parse(tagName)
-		logrus.Infof("looking at tag %s", tagName)
-		if err != nil {
-			logrus.WithError(err).Debugf("tag (%s) not valid semver, skipping", tagName) // but we continue looking for tags that are
-			return nil
-		}
-
-		if len(version.Pre) > 0 || len(version.Build) > 0 {
-			logrus.Debugf("tag (%s) looks like a prerelease or a build, skipping", tagName)
-			return nil
-		}
-
-		tagIndex[tag.Hash().String()] = tagName
-		return nil
-	})
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "error iterating over repo tags")
+		return nil, nil, err
 	}
 
 	commit := branchCommit
@@ -91,8 +80,8 @@ func LatestTagCommitHash(repo GitRepoIface, branchRef string) (*string, *plumbin
 	}
 
 	err = gitLog.ForEach(func(c *object.Commit) error {
-		if v, ok := tagIndex[c.Hash.String()]; ok {
-			latestVersionTag = v
+		if v, ok := tagIndex[c.Hash]; ok {
+			latestVersionTag = v.String()
 			latestVersionHash = c.Hash
 			return storer.ErrStop
 		}
@@ -107,6 +96,94 @@ func LatestTagCommitHash(repo GitRepoIface, branchRef string) (*string, *plumbin
 
 }
 
+// VersionTags maps the commits tagged with tagPrefix + version to their version. Build tags are skipped, and so are
+// pre-release tags unless preReleases. When a commit has several versions, e.g. 1.4.0-rc.2 and 1.4.0, the highest wins.
+func VersionTags(repo GitRepoIface, tagPrefix string, preReleases bool) (map[plumbing.Hash]semver.Version, error) {
+	tagIndex := make(map[plumbing.Hash]semver.Version)
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch repo tags")
+	}
+
+	err = tags.ForEach(func(tag *plumbing.Reference) error {
+		if !strings.HasPrefix(tag.Name().Short(), tagPrefix) {
+			return nil
+		}
+		tagName := strings.TrimPrefix(tag.Name().Short(), tagPrefix)
+		version, err := semver.Parse(tagName)
+		logrus.Debugf("looking at tag %s", tagName)
+		if err != nil {
+			logrus.WithError(err).Debugf("tag (%s) not valid semver, skipping", tagName) // but we continue looking for tags that are
+			return nil
+		}
+
+		if (len(version.Pre) > 0 && !preReleases) || len(version.Build) > 0 {
+			logrus.Debugf("tag (%s) looks like a prerelease or a build, skipping", tagName)
+			return nil
+		}
+
+		hash, err := TagCommitHash(repo, tag)
+		if err != nil {
+			return err
+		}
+		// a commit can carry several versions, e.g. 1.4.0-rc.2 and 1.4.0
+		if existing, ok := tagIndex[hash]; ok && existing.GT(version) {
+			return nil
+		}
+		tagIndex[hash] = version
+		return nil
+	})
+	return tagIndex, errors.Wrap(err, "error iterating over repo tags")
+}
+
+// LatestPreReleaseNumber returns the highest N of the tags named tagPrefix + core-channel.N, or 0 if there are none
+func LatestPreReleaseNumber(repo GitRepoIface, tagPrefix string, core semver.Version, channel string) (uint64, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not fetch repo tags")
+	}
+
+	var latest uint64
+	err = tags.ForEach(func(tag *plumbing.Reference) error {
+		if !strings.HasPrefix(tag.Name().Short(), tagPrefix) {
+			return nil
+		}
+		version, err := semver.Parse(strings.TrimPrefix(tag.Name().Short(), tagPrefix))
+		if err != nil {
+			return nil
+		}
+		if version.Major != core.Major || version.Minor != core.Minor || version.Patch != core.Patch {
+			return nil
+		}
+		if len(version.Pre) != 2 || version.Pre[0].String() != channel || !version.Pre[1].IsNum {
+			return nil
+		}
+		if version.Pre[1].VersionNum > latest {
+			latest = version.Pre[1].VersionNum
+		}
+		return nil
+	})
+	return latest, errors.Wrap(err, "error iterating over repo tags")
+}
+
+// TagCommitHash returns the hash of the commit a tag points to. Lightweight tags point to the commit directly,
+// annotated tags point to a tag object.
+func TagCommitHash(repo GitRepoIface, tag *plumbing.Reference) (plumbing.Hash, error) {
+	tagObject, err := repo.TagObject(tag.Hash())
+	if err == plumbing.ErrObjectNotFound {
+		return tag.Hash(), nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, errors.Wrapf(err, "unable to read tag %s", tag.Name().Short())
+	}
+	commit, err := tagObject.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, errors.Wrapf(err, "tag %s does not point to a commit", tag.Name().Short())
+	}
+	return commit.Hash, nil
+}
+
 // VerifyDefaultBranch returns the default branch's commit, according to HEAD
 func VerifyDefaultBranch(repo GitRepoIface, defaultBranchRef string) (*object.Commit, error) {
 	headRef, err := repo.Head()
@@ -150,3 +227,168 @@ func GetLatestParentCommit(commit *object.Commit) (*object.Commit, error) {
 	}
 	return recentParentCommit, nil
 }
+
+// CommitsSince walks back from commit, following the most recent parent, and returns every commit
+// until (excluding) the commit with hash until or the beginning of history. Newest commits come first.
+func CommitsSince(commit *object.Commit, until plumbing.Hash) ([]*object.Commit, error) {
+	result := []*object.Commit{}
+	for {
+		if commit.Hash == until {
+			return result, nil
+		}
+		result = append(result, commit)
+
+		if len(commit.ParentHashes) == 0 {
+			// When we get here we should be at the beginning of this repo's history
+			return result, nil
+		}
+		var err error
+		commit, err = GetLatestParentCommit(commit)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// CommitsBetween returns the commits reachable from to but not from from, like git log from..to. With the zero hash
+// as from, the whole history of to is returned. Children always come before their parents, otherwise the most
+// recently committed commits come first.
+func CommitsBetween(repo GitRepoIface, to *object.Commit, from plumbing.Hash) ([]*object.Commit, error) {
+	excluded := map[plumbing.Hash]bool{}
+	if !from.IsZero() {
+		fromCommit, err := repo.CommitObject(from)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to find commit %s", from)
+		}
+		err = object.NewCommitPreorderIter(fromCommit, nil, nil).ForEach(func(c *object.Commit) error {
+			excluded[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to walk the history of %s", from)
+		}
+	}
+
+	all := []*object.Commit{}
+	err := object.NewCommitIterCTime(to, excluded, nil).ForEach(func(c *object.Commit) error {
+		all = append(all, c)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to walk the history of %s", to.Hash)
+	}
+
+	// commits can share a committer time, e.g. when committed by a script, so order parents after their children
+	children := map[plumbing.Hash]int{}
+	for _, c := range all {
+		for _, parent := range c.ParentHashes {
+			children[parent]++
+		}
+	}
+	ready := &commitHeap{}
+	for _, c := range all {
+		if children[c.Hash] == 0 {
+			heap.Push(ready, c)
+		}
+	}
+	inRange := map[plumbing.Hash]*object.Commit{}
+	for _, c := range all {
+		inRange[c.Hash] = c
+	}
+
+	ordered := make([]*object.Commit, 0, len(all))
+	for ready.Len() > 0 {
+		c := heap.Pop(ready).(*object.Commit)
+		ordered = append(ordered, c)
+		for _, parent := range c.ParentHashes {
+			children[parent]--
+			if p, ok := inRange[parent]; ok && children[parent] == 0 {
+				heap.Push(ready, p)
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// commitHeap pops the most recently committed commit first
+type commitHeap []*object.Commit
+
+func (h commitHeap) Len() int            { return len(h) }
+func (h commitHeap) Less(i, j int) bool  { return h[i].Committer.When.After(h[j].Committer.When) }
+func (h commitHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *commitHeap) Push(x interface{}) { *h = append(*h, x.(*object.Commit)) }
+func (h *commitHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// ChangedPaths returns the paths of the files changed by commit, compared to its first parent.
+// For the first commit of a repo every file is returned.
+func ChangedPaths(commit *object.Commit) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read tree of commit %s", commit.Hash)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read parent of commit %s", commit.Hash)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read tree of commit %s", parent.Hash)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to diff commit %s", commit.Hash)
+	}
+
+	paths := []string{}
+	for _, change := range changes {
+		// renames show up with both names
+		if change.From.Name != "" {
+			paths = append(paths, change.From.Name)
+		}
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			paths = append(paths, change.To.Name)
+		}
+	}
+	return paths, nil
+}
+
+// LatestPreReleaseTag returns the highest pre-release version among the tags named tagPrefix + version,
+// and the hash the tag points to. The version is nil if there are no pre-release tags.
+func LatestPreReleaseTag(repo GitRepoIface, tagPrefix string) (*semver.Version, plumbing.Hash, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, plumbing.ZeroHash, errors.Wrap(err, "could not fetch repo tags")
+	}
+
+	var latest *semver.Version
+	var latestHash plumbing.Hash
+	err = tags.ForEach(func(tag *plumbing.Reference) error {
+		if !strings.HasPrefix(tag.Name().Short(), tagPrefix) {
+			return nil
+		}
+		version, err := semver.Parse(strings.TrimPrefix(tag.Name().Short(), tagPrefix))
+		if err != nil || len(version.Pre) == 0 || len(version.Build) > 0 {
+			return nil
+		}
+		if latest == nil || version.GT(*latest) {
+			hash, err := TagCommitHash(repo, tag)
+			if err != nil {
+				return err
+			}
+			latest = &version
+			latestHash = hash
+		}
+		return nil
+	})
+	return latest, latestHash, errors.Wrap(err, "error iterating over repo tags")
+}
@@ -0,0 +1,245 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/chanzuckerberg/bff/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func TestLatestTagCommitHash(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "0.1.0"})
+	r.tag("v0.1.0", first)
+	second := r.commit("release version 0.2.0", map[string]string{"VERSION": "0.2.0"})
+	r.tag("v0.2.0", second)
+	r.tag("v0.3.0-rc.1", r.commit("feat: rc", map[string]string{"a": "a"}))
+	r.tag("other-1.0.0", r.commit("fix: other", map[string]string{"b": "b"}))
+
+	version, hash, err := util.LatestTagCommitHash(r.repo, testBranchRef, "v")
+	a.NoError(err)
+	a.Equal("0.2.0", *version)
+	a.Equal(second, *hash)
+
+	version, _, err = util.LatestTagCommitHash(r.repo, testBranchRef, "other-")
+	a.NoError(err)
+	a.Equal("1.0.0", *version)
+}
+
+func TestCommitsSince(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "0.1.0"})
+	second := r.commit("feat: second", map[string]string{"a": "a"})
+	third := r.commit("fix: third", map[string]string{"b": "b"})
+
+	commits, err := util.CommitsSince(r.commitObject(third), first)
+	a.NoError(err)
+	a.Len(commits, 2)
+	a.Equal(third, commits[0].Hash)
+	a.Equal(second, commits[1].Hash)
+
+	commits, err = util.CommitsSince(r.commitObject(third), third)
+	a.NoError(err)
+	a.Empty(commits)
+
+	// without a release, the whole history is returned
+	commits, err = util.CommitsSince(r.commitObject(third), plumbing.ZeroHash)
+	a.NoError(err)
+	a.Len(commits, 3)
+}
+
+func TestLatestTagCommitHashWithPreReleases(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	r.tag("v1.3.0", r.commit("initial commit", map[string]string{"VERSION": "1.3.0"}))
+	rc := r.commit("release version 1.4.0-rc.1", map[string]string{"VERSION": "1.4.0-rc.1"})
+	r.tag("v1.4.0-rc.1", rc)
+	r.commit("fix: after rc", map[string]string{"a": "a"})
+
+	version, hash, err := util.LatestTagCommitHashWithPreReleases(r.repo, testBranchRef, "v")
+	a.NoError(err)
+	a.Equal("1.4.0-rc.1", *version)
+	a.Equal(rc, *hash)
+
+	// a stable tag on the same commit wins
+	r.tag("v1.4.0", rc)
+	version, _, err = util.LatestTagCommitHashWithPreReleases(r.repo, testBranchRef, "v")
+	a.NoError(err)
+	a.Equal("1.4.0", *version)
+}
+
+func TestLatestPreReleaseNumber(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	hash := r.commit("initial commit", map[string]string{"VERSION": "1.3.0"})
+	r.tag("v1.4.0-rc.1", hash)
+	r.tag("v1.4.0-rc.2", hash)
+	r.tag("v1.4.0-beta.5", hash)
+	r.tag("v1.5.0-rc.7", hash)
+
+	n, err := util.LatestPreReleaseNumber(r.repo, "v", semver.MustParse("1.4.0"), "rc")
+	a.NoError(err)
+	a.Equal(uint64(2), n)
+
+	n, err = util.LatestPreReleaseNumber(r.repo, "v", semver.MustParse("1.4.0"), "alpha")
+	a.NoError(err)
+	a.Equal(uint64(0), n)
+}
+
+func TestChangedPaths(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "0.1.0", "api/main.go": "package main"})
+	second := r.commit("feat: change api", map[string]string{"api/main.go": "package main\n", "README.md": "# readme"})
+
+	paths, err := util.ChangedPaths(r.commitObject(first))
+	a.NoError(err)
+	a.ElementsMatch([]string{"VERSION", "api/main.go"}, paths)
+
+	paths, err = util.ChangedPaths(r.commitObject(second))
+	a.NoError(err)
+	a.ElementsMatch([]string{"README.md", "api/main.go"}, paths)
+}
+
+func TestLatestPreReleaseTag(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	version, _, err := util.LatestPreReleaseTag(r.repo, "v")
+	a.NoError(err)
+	a.Nil(version)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "1.4.0-rc.1"})
+	second := r.commit("fix: second", map[string]string{"VERSION": "1.4.0-rc.2"})
+	r.tag("v1.3.0", first)
+	r.tag("v1.4.0-rc.1", first)
+	r.tag("v1.4.0-rc.2", second)
+	r.tag("v1.4.0-beta.9", second)
+
+	version, hash, err := util.LatestPreReleaseTag(r.repo, "v")
+	a.NoError(err)
+	a.Equal("1.4.0-rc.2", version.String())
+	a.Equal(second, hash)
+}
+
+func TestAnnotatedTags(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "0.1.0"})
+	r.annotatedTag("v0.1.0", first)
+	second := r.commit("feat: rc", map[string]string{"a": "a"})
+	r.annotatedTag("v0.2.0-rc.1", second)
+	r.commit("fix: after", map[string]string{"b": "b"})
+
+	version, hash, err := util.LatestTagCommitHash(r.repo, testBranchRef, "v")
+	a.NoError(err)
+	a.Equal("0.1.0", *version)
+	a.Equal(first, *hash)
+
+	version, hash, err = util.LatestTagCommitHashWithPreReleases(r.repo, testBranchRef, "v")
+	a.NoError(err)
+	a.Equal("0.2.0-rc.1", *version)
+	a.Equal(second, *hash)
+
+	pre, preHash, err := util.LatestPreReleaseTag(r.repo, "v")
+	a.NoError(err)
+	a.Equal("0.2.0-rc.1", pre.String())
+	a.Equal(second, preHash)
+}
+
+func TestVersionTags(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "0.1.0"})
+	r.tag("v0.1.0", first)
+	second := r.commit("feat: rc", map[string]string{"a": "a"})
+	r.tag("v0.2.0-rc.1", second)
+	r.annotatedTag("v0.2.0", second)
+	third := r.commit("feat: next", map[string]string{"b": "b"})
+	r.tag("v0.3.0-rc.1", third)
+	r.tag("v0.3.0+build.1", third)
+	r.tag("other-1.0.0", third)
+
+	tags, err := util.VersionTags(r.repo, "v", false)
+	a.NoError(err)
+	a.Equal(map[plumbing.Hash]semver.Version{
+		first:  semver.MustParse("0.1.0"),
+		second: semver.MustParse("0.2.0"),
+	}, tags)
+
+	tags, err = util.VersionTags(r.repo, "v", true)
+	a.NoError(err)
+	a.Len(tags, 3)
+	a.Equal(semver.MustParse("0.2.0"), tags[second])
+	a.Equal(semver.MustParse("0.3.0-rc.1"), tags[third])
+}
+
+func TestCommitsBetween(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "0.1.0"})
+	// scripted commits share a committer time
+	r.when = r.when.Add(-time.Hour)
+	second := r.commit("feat: second", map[string]string{"a": "a"})
+	r.when = r.when.Add(-time.Hour)
+	third := r.commit("fix: third", map[string]string{"b": "b"})
+
+	commits, err := util.CommitsBetween(r.repo, r.commitObject(third), plumbing.ZeroHash)
+	a.NoError(err)
+	a.Len(commits, 3)
+	a.Equal(third, commits[0].Hash)
+	a.Equal(second, commits[1].Hash)
+	a.Equal(first, commits[2].Hash)
+
+	commits, err = util.CommitsBetween(r.repo, r.commitObject(third), first)
+	a.NoError(err)
+	a.Len(commits, 2)
+	a.Equal(third, commits[0].Hash)
+
+	commits, err = util.CommitsBetween(r.repo, r.commitObject(third), third)
+	a.NoError(err)
+	a.Empty(commits)
+}
+
+func TestCommitsBetweenMerge(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRepo(t)
+
+	first := r.commit("initial commit", map[string]string{"VERSION": "1.0.0"})
+	r.checkout("feature", first)
+	feature := r.commit("feat: branch feature", map[string]string{"feature": "feature"})
+	r.checkout("master", plumbing.ZeroHash)
+	fix := r.commit("fix: patch", map[string]string{"fix": "fix"})
+	merge := r.commit("Merge branch feature", map[string]string{"feature": "feature"}, feature)
+
+	// the branch was merged after the fix, its commit is older but only reachable from the merge
+	commits, err := util.CommitsBetween(r.repo, r.commitObject(merge), fix)
+	a.NoError(err)
+	a.Len(commits, 2)
+	a.Equal(merge, commits[0].Hash)
+	a.Equal(feature, commits[1].Hash)
+
+	commits, err = util.CommitsBetween(r.repo, r.commitObject(fix), first)
+	a.NoError(err)
+	a.Len(commits, 1)
+	a.Equal(fix, commits[0].Hash)
+
+	commits, err = util.CommitsBetween(r.repo, r.commitObject(merge), plumbing.ZeroHash)
+	a.NoError(err)
+	a.Len(commits, 4)
+	a.Equal(merge, commits[0].Hash)
+	a.Equal(first, commits[3].Hash)
+}
@@ -0,0 +1,106 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+const testBranchRef = "refs/heads/master"
+
+// testRepo is an in-memory git repository to build histories for tests
+type testRepo struct {
+	t    *testing.T
+	repo *git.Repository
+	w    *git.Worktree
+	when time.Time
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testRepo{t: t, repo: repo, w: w, when: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// commit writes files (path -> content) and commits them, with the given parents after HEAD for merges
+func (r *testRepo) commit(message string, files map[string]string, parents ...plumbing.Hash) plumbing.Hash {
+	for path, content := range files {
+		f, err := r.w.Filesystem.Create(path)
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		_, err = f.Write([]byte(content))
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		f.Close()
+		_, err = r.w.Add(path)
+		if err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.when = r.when.Add(time.Hour)
+	if len(parents) > 0 {
+		head, err := r.repo.Head()
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		parents = append([]plumbing.Hash{head.Hash()}, parents...)
+	}
+	hash, err := r.w.Commit(message, &git.CommitOptions{
+		Author:  &object.Signature{Name: "Current User", Email: "user@example.com", When: r.when},
+		Parents: parents,
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return hash
+}
+
+// checkout switches to the branch, creating it at from unless from is the zero hash
+func (r *testRepo) checkout(branch string, from plumbing.Hash) {
+	err := r.w.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branch),
+		Create: !from.IsZero(),
+		Hash:   from,
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *testRepo) tag(name string, hash plumbing.Hash) {
+	_, err := r.repo.CreateTag(name, hash, nil)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *testRepo) commitObject(hash plumbing.Hash) *object.Commit {
+	c, err := r.repo.CommitObject(hash)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return c
+}
+
+func (r *testRepo) annotatedTag(name string, hash plumbing.Hash) {
+	_, err := r.repo.CreateTag(name, hash, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "Current User", Email: "user@example.com", When: r.when},
+		Message: "release " + name,
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+)
+
+// ReadSigningKey reads the OpenPGP keyring file at keyringPath, armored or binary, and returns the private key
+// to sign with. keyID selects the key by its (long or short) hex ID or a substring of one of its user IDs,
+// if empty the first private key is used. Encrypted keys are decrypted with passphrase.
+func ReadSigningKey(keyringPath string, keyID string, passphrase string) (*openpgp.Entity, error) {
+	data, err := ioutil.ReadFile(keyringPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read keyring %s", keyringPath)
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse keyring %s", keyringPath)
+	}
+
+	var key *openpgp.Entity
+	for _, entity := range keyring {
+		if entity.PrivateKey != nil && matchesKeyID(entity, keyID) {
+			key = entity
+			break
+		}
+	}
+	if key == nil {
+		if keyID == "" {
+			return nil, errors.Errorf("there is no private key in keyring %s", keyringPath)
+		}
+		return nil, errors.Errorf("there is no private key %s in keyring %s", keyID, keyringPath)
+	}
+
+	if key.PrivateKey.Encrypted {
+		if passphrase == "" {
+			return nil, errors.Errorf("the signing key %s is encrypted, a passphrase is required", key.PrimaryKey.KeyIdString())
+		}
+		err = key.PrivateKey.Decrypt([]byte(passphrase))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to decrypt the signing key %s", key.PrimaryKey.KeyIdString())
+		}
+		for _, subkey := range key.Subkeys {
+			if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+				err = subkey.PrivateKey.Decrypt([]byte(passphrase))
+				if err != nil {
+					return nil, errors.Wrapf(err, "unable to decrypt the signing subkey %s", subkey.PublicKey.KeyIdString())
+				}
+			}
+		}
+	}
+	return key, nil
+}
+
+func matchesKeyID(entity *openpgp.Entity, keyID string) bool {
+	if keyID == "" {
+		return true
+	}
+	id := strings.ToUpper(strings.TrimPrefix(keyID, "0x"))
+	if strings.HasSuffix(fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint), id) {
+		return true
+	}
+	for name := range entity.Identities {
+		if strings.Contains(name, keyID) {
+			return true
+		}
+	}
+	return false
+}
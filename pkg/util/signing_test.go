@@ -0,0 +1,73 @@
+package util_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func writeKeyring(t *testing.T, dir string, entities ...*openpgp.Entity) string {
+	path := filepath.Join(dir, "keyring.asc")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w, err := armor.Encode(f, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entities {
+		err = e.SerializePrivate(w, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadSigningKey(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "bff-keyring")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+
+	releaser, err := openpgp.NewEntity("Release Bot", "", "release@example.com", nil)
+	a.NoError(err)
+	other, err := openpgp.NewEntity("Someone Else", "", "else@example.com", nil)
+	a.NoError(err)
+	keyring := writeKeyring(t, dir, other, releaser)
+
+	key, err := util.ReadSigningKey(keyring, "", "")
+	a.NoError(err)
+	a.Equal(other.PrimaryKey.KeyId, key.PrimaryKey.KeyId)
+
+	key, err = util.ReadSigningKey(keyring, "release@example.com", "")
+	a.NoError(err)
+	a.Equal(releaser.PrimaryKey.KeyId, key.PrimaryKey.KeyId)
+
+	key, err = util.ReadSigningKey(keyring, "0x"+releaser.PrimaryKey.KeyIdString(), "")
+	a.NoError(err)
+	a.Equal(releaser.PrimaryKey.KeyId, key.PrimaryKey.KeyId)
+
+	_, err = util.ReadSigningKey(keyring, "nobody@example.com", "")
+	a.Error(err)
+
+	_, err = util.ReadSigningKey(filepath.Join(dir, "missing.asc"), "", "")
+	a.Error(err)
+
+	garbage := filepath.Join(dir, "garbage")
+	a.NoError(ioutil.WriteFile(garbage, []byte("not a keyring"), 0644))
+	_, err = util.ReadSigningKey(garbage, "", "")
+	a.Error(err)
+}
@@ -0,0 +1,193 @@
+package versionfile
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type jsonUpdater struct {
+	keys []string
+}
+
+// Update replaces the string value of the top level keys
+func (u jsonUpdater) Update(content []byte, version string) ([]byte, error) {
+	s := string(content)
+	found := false
+	for _, key := range u.keys {
+		start, end, ok := findTopLevelJSONValue(s, key)
+		if !ok {
+			continue
+		}
+		s = s[:start] + strconv.Quote(version) + s[end:]
+		found = true
+	}
+	if !found {
+		return nil, errNotFound(JSON, u.keys)
+	}
+	return []byte(s), nil
+}
+
+// findTopLevelJSONValue returns the span of the string value of key in the top level object, quotes included
+func findTopLevelJSONValue(s string, key string) (int, int, bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case '"':
+			end := skipJSONString(s, i)
+			if depth == 1 {
+				name, err := strconv.Unquote(s[i:end])
+				next := skipSpace(s, end)
+				if err == nil && name == key && next < len(s) && s[next] == ':' {
+					valueStart := skipSpace(s, next+1)
+					if valueStart < len(s) && s[valueStart] == '"' {
+						return valueStart, skipJSONString(s, valueStart), true
+					}
+				}
+			}
+			i = end - 1
+		}
+	}
+	return 0, 0, false
+}
+
+// skipJSONString returns the index just after the string starting with the quote at start
+func skipJSONString(s string, start int) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && strings.ContainsRune(" \t\r\n", rune(s[i])) {
+		i++
+	}
+	return i
+}
+
+type yamlUpdater struct {
+	keys []string
+}
+
+// Update replaces the scalar value of the top level keys, keeping any quotes and comments
+func (u yamlUpdater) Update(content []byte, version string) ([]byte, error) {
+	s := string(content)
+	found := false
+	for _, key := range u.keys {
+		r := regexp.MustCompile(fmt.Sprintf(`(?m)^(%s:[ \t]*["']?)([^\s"'#]*)`, regexp.QuoteMeta(key)))
+		loc := r.FindStringSubmatchIndex(s)
+		if loc == nil {
+			continue
+		}
+		s = s[:loc[4]] + version + s[loc[5]:]
+		found = true
+	}
+	if !found {
+		return nil, errNotFound(YAML, u.keys)
+	}
+	return []byte(s), nil
+}
+
+var (
+	tomlTableRegexp = regexp.MustCompile(`^\s*\[\[?\s*([^\[\]]+?)\s*\]\]?\s*(#.*)?$`)
+	tomlKeyRegexp   = regexp.MustCompile(`^(\s*)([A-Za-z0-9_.-]+)(\s*=\s*["'])([^"']*)(["'])`)
+)
+
+type tomlUpdater struct {
+	keys []string
+}
+
+// Update replaces the string value of the table.key keys
+func (u tomlUpdater) Update(content []byte, version string) ([]byte, error) {
+	wanted := map[string]bool{}
+	for _, key := range u.keys {
+		wanted[key] = true
+	}
+
+	lines := strings.Split(string(content), "\n")
+	table := ""
+	found := false
+	for i, line := range lines {
+		if match := tomlTableRegexp.FindStringSubmatch(strings.TrimRight(line, "\r")); match != nil {
+			table = match[1]
+			continue
+		}
+		match := tomlKeyRegexp.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		key := line[match[4]:match[5]]
+		if table != "" {
+			key = table + "." + key
+		}
+		if !wanted[key] {
+			continue
+		}
+		lines[i] = line[:match[8]] + version + line[match[9]:]
+		delete(wanted, key)
+		found = true
+	}
+	if !found {
+		return nil, errNotFound(TOML, u.keys)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+type goUpdater struct {
+	names []string
+}
+
+// goConstBlock matches `const (...)` blocks
+var goConstBlock = regexp.MustCompile(`(?ms)^[ \t]*const[ \t]*\(.*?^[ \t]*\)`)
+
+// Update replaces the value of `const Name = "..."` declarations, also inside const blocks
+func (u goUpdater) Update(content []byte, version string) ([]byte, error) {
+	s := string(content)
+	found := false
+	for _, name := range u.names {
+		start, end, ok := goConstValue(s, name)
+		if !ok {
+			continue
+		}
+		s = s[:start] + strconv.Quote(version) + s[end:]
+		found = true
+	}
+	if !found {
+		return nil, errNotFound(Go, u.names)
+	}
+	return []byte(s), nil
+}
+
+// goConstValue returns the location of the quoted value of the first string constant name, declared
+// either on its own or inside a const block. Variables and assignments are left alone.
+func goConstValue(s string, name string) (int, int, bool) {
+	spec := fmt.Sprintf(`%s(?:[ \t]+string)?[ \t]*=[ \t]*("[^"\n]*")`, regexp.QuoteMeta(name))
+	single := regexp.MustCompile(`(?m)^[ \t]*const[ \t]+` + spec)
+	inBlock := regexp.MustCompile(`(?m)^[ \t]*` + spec)
+
+	start, end := -1, -1
+	if loc := single.FindStringSubmatchIndex(s); loc != nil {
+		start, end = loc[2], loc[3]
+	}
+	for _, block := range goConstBlock.FindAllStringIndex(s, -1) {
+		if start >= 0 && block[0] > start {
+			break
+		}
+		if loc := inBlock.FindStringSubmatchIndex(s[block[0]:block[1]]); loc != nil {
+			start, end = block[0]+loc[2], block[0]+loc[3]
+			break
+		}
+	}
+	return start, end, start >= 0
+}
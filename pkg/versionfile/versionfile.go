@@ -0,0 +1,100 @@
+package versionfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Formats of version files
+const (
+	// Plain is a file that holds nothing but the version, e.g. VERSION
+	Plain = "plain"
+	// JSON is a JSON document with the version in a top level key, e.g. package.json
+	JSON = "json"
+	// YAML is a YAML document with the version in top level keys, e.g. a Helm Chart.yaml
+	YAML = "yaml"
+	// TOML is a TOML document with the version in table.key keys, e.g. Cargo.toml or pyproject.toml
+	TOML = "toml"
+	// Go is a Go source file with the version in a `const Version = "..."` declaration
+	Go = "go"
+)
+
+// Updater rewrites the version in the content of a file in place, leaving everything else as is
+type Updater interface {
+	Update(content []byte, version string) ([]byte, error)
+}
+
+// New returns the updater for the file at filePath. The format is inferred from the file name if empty,
+// and keys default to the usual place of the version for the format.
+func New(filePath string, format string, keys []string) (Updater, error) {
+	if format == "" {
+		format = inferFormat(filePath)
+	}
+	if len(keys) == 0 {
+		keys = defaultKeys(filePath, format)
+	}
+
+	switch format {
+	case Plain:
+		return plainUpdater{}, nil
+	case JSON:
+		return jsonUpdater{keys: keys}, nil
+	case YAML:
+		return yamlUpdater{keys: keys}, nil
+	case TOML:
+		return tomlUpdater{keys: keys}, nil
+	case Go:
+		return goUpdater{names: keys}, nil
+	default:
+		return nil, errors.Errorf("unknown version file format %s for %s", format, filePath)
+	}
+}
+
+func inferFormat(filePath string) string {
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".json":
+		return JSON
+	case ".yaml", ".yml":
+		return YAML
+	case ".toml":
+		return TOML
+	case ".go":
+		return Go
+	default:
+		return Plain
+	}
+}
+
+func defaultKeys(filePath string, format string) []string {
+	switch format {
+	case TOML:
+		switch path.Base(filePath) {
+		case "Cargo.toml":
+			return []string{"package.version"}
+		case "pyproject.toml":
+			return []string{"project.version", "tool.poetry.version"}
+		}
+		return []string{"version"}
+	case Go:
+		return []string{"Version"}
+	default:
+		return []string{"version"}
+	}
+}
+
+// errNotFound is returned when none of the keys are in the file
+func errNotFound(format string, keys []string) error {
+	return errors.Errorf("could not find %s in the %s file", strings.Join(keys, " or "), format)
+}
+
+type plainUpdater struct{}
+
+// Update replaces the whole content, keeping a trailing newline if there was one
+func (plainUpdater) Update(content []byte, version string) ([]byte, error) {
+	if strings.HasSuffix(string(content), "\n") {
+		return []byte(version + "\n"), nil
+	}
+	return []byte(version), nil
+}
@@ -0,0 +1,108 @@
+package versionfile_test
+
+import (
+	"testing"
+
+	"github.com/chanzuckerberg/bff/pkg/versionfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	var tests = []struct {
+		name    string
+		path    string
+		format  string
+		keys    []string
+		content string
+		want    string
+	}{
+		{"plain", "VERSION", "", nil, "1.0.0", "1.1.0"},
+		{"plain newline", "VERSION", "", nil, "1.0.0\n", "1.1.0\n"},
+		{
+			"package.json", "package.json", "", nil,
+			"{\n  \"name\": \"app\",\n  \"dependencies\": {\"version\": \"9.9.9\"},\n  \"version\": \"1.0.0\",\n  \"private\": true\n}\n",
+			"{\n  \"name\": \"app\",\n  \"dependencies\": {\"version\": \"9.9.9\"},\n  \"version\": \"1.1.0\",\n  \"private\": true\n}\n",
+		},
+		{
+			"json string containing the key", "package.json", "", nil,
+			`{"description": "\"version\": no", "version" : "1.0.0"}`,
+			`{"description": "\"version\": no", "version" : "1.1.0"}`,
+		},
+		{
+			"Chart.yaml", "Chart.yaml", "", []string{"version", "appVersion"},
+			"apiVersion: v2\nname: app\nversion: 1.0.0 # chart\nappVersion: \"1.0.0\"\ndependencies:\n  - name: db\n    version: 2.0.0\n",
+			"apiVersion: v2\nname: app\nversion: 1.1.0 # chart\nappVersion: \"1.1.0\"\ndependencies:\n  - name: db\n    version: 2.0.0\n",
+		},
+		{
+			"pyproject.toml", "pyproject.toml", "", nil,
+			"[build-system]\nrequires = [\"setuptools\"]\n\n[project]\nname = \"app\"\nversion = \"1.0.0\"\n",
+			"[build-system]\nrequires = [\"setuptools\"]\n\n[project]\nname = \"app\"\nversion = \"1.1.0\"\n",
+		},
+		{
+			"Cargo.toml", "crates/app/Cargo.toml", "", nil,
+			"[package]\r\nname = \"app\"\r\nversion = '1.0.0' # keep\r\n\r\n[dependencies]\r\nserde = { version = \"1\" }\r\n",
+			"[package]\r\nname = \"app\"\r\nversion = '1.1.0' # keep\r\n\r\n[dependencies]\r\nserde = { version = \"1\" }\r\n",
+		},
+		{
+			"toml dotted key", "Cargo.toml", "", nil,
+			"package.name = \"app\"\npackage.version = \"1.0.0\"\n",
+			"package.name = \"app\"\npackage.version = \"1.1.0\"\n",
+		},
+		{
+			"go const", "version.go", "", nil,
+			"package app\n\n// Version of the app\nconst Version = \"1.0.0\"\n",
+			"package app\n\n// Version of the app\nconst Version = \"1.1.0\"\n",
+		},
+		{
+			"go const block", "pkg/version/version.go", "", nil,
+			"package version\n\nconst (\n\tName           = \"app\"\n\tVersion string = \"1.0.0\"\n)\n",
+			"package version\n\nconst (\n\tName           = \"app\"\n\tVersion string = \"1.1.0\"\n)\n",
+		},
+		{
+			"go const after local var", "version.go", "", nil,
+			"package app\n\nfunc f() {\n\tvar Version = \"dev\"\n\t_ = Version\n}\n\nconst (\n\tVersion = \"1.0.0\"\n)\n",
+			"package app\n\nfunc f() {\n\tvar Version = \"dev\"\n\t_ = Version\n}\n\nconst (\n\tVersion = \"1.1.0\"\n)\n",
+		},
+		{"explicit format", "version.txt", "json", nil, `{"version": "1.0.0"}`, `{"version": "1.1.0"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := assert.New(t)
+			u, err := versionfile.New(test.path, test.format, test.keys)
+			a.NoError(err)
+			got, err := u.Update([]byte(test.content), "1.1.0")
+			a.NoError(err)
+			a.Equal(test.want, string(got))
+		})
+	}
+}
+
+func TestUpdateNotFound(t *testing.T) {
+	var tests = []struct {
+		path    string
+		content string
+	}{
+		{"package.json", `{"dependencies": {"version": "1.0.0"}}`},
+		{"Chart.yaml", "dependencies:\n  - version: 1.0.0\n"},
+		{"Cargo.toml", "[dependencies]\nversion = \"1.0.0\"\n"},
+		{"version.go", "package app\n\nconst Name = \"app\"\n"},
+		{"version.go", "package app\n\nvar Version = \"1.0.0\"\n"},
+		{"version.go", "package app\n\nvar (\n\tVersion = \"1.0.0\"\n)\n"},
+		{"version.go", "package app\n\nfunc init() {\n\tVersion = \"1.0.0\"\n}\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			u, err := versionfile.New(test.path, "", nil)
+			assert.NoError(t, err)
+			_, err = u.Update([]byte(test.content), "1.1.0")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewUnknownFormat(t *testing.T) {
+	_, err := versionfile.New("VERSION", "xml", nil)
+	assert.Error(t, err)
+}